@@ -24,36 +24,144 @@ import (
 
 const (
 	ElastiServiceFinalizer = "elasti.truefoundry.com/finalizer"
+
+	// TriggerPolicyAll scales to zero only when all the triggers are idle
+	TriggerPolicyAll = "all"
+	// TriggerPolicyAny scales to zero when any of the triggers is idle
+	TriggerPolicyAny = "any"
+
+	TriggerResultIdle      = "idle"
+	TriggerResultActive    = "active"
+	TriggerResultUnhealthy = "unhealthy"
+
+	// WakeReplicasPolicyMin wakes the target up with minTargetReplicas
+	WakeReplicasPolicyMin = "min"
+	// WakeReplicasPolicyPrevious wakes the target up with the replicas it had before it was scaled down
+	WakeReplicasPolicyPrevious = "previous"
+	// WakeReplicasPolicyMax wakes the target up with the most of minTargetReplicas and the replicas it had before it was scaled down
+	WakeReplicasPolicyMax = "max"
+
+	// ConditionTriggersValid reports whether the triggers and the triggerPolicy are valid,
+	// the triggers of an ElastiService for which it is false aren't evaluated
+	ConditionTriggersValid = "TriggersValid"
+	// ReasonTriggersValid and ReasonInvalidTriggers are the reasons of the TriggersValid condition
+	ReasonTriggersValid   = "Valid"
+	ReasonInvalidTriggers = "InvalidTriggers"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ElastiServiceSpec defines the desired state of ElastiService
+// +kubebuilder:validation:XValidation:rule="!has(self.idleReplicas) || self.idleReplicas < (has(self.minTargetReplicas) ? self.minTargetReplicas : 1)",message="idleReplicas must be lower than minTargetReplicas"
+// +kubebuilder:validation:XValidation:rule="has(self.scaleTargetRef) != has(self.scaleTargetRefs)",message="exactly one of scaleTargetRef or scaleTargetRefs must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.idleReplicas) || !has(self.scaleTargetRefs) || self.scaleTargetRefs.all(t, !has(t.minReplicas) || self.idleReplicas < t.minReplicas)",message="idleReplicas must be lower than the minReplicas of the scaleTargetRefs"
 type ElastiServiceSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
-	// +kubebuilder:validation:Required
-	ScaleTargetRef ScaleTargetRef `json:"scaleTargetRef,omitempty"`
+	// ScaleTargetRef is the target scaled with the requests, it is woken up with minTargetReplicas.
+	// Exactly one of scaleTargetRef or scaleTargetRefs is set.
+	ScaleTargetRef *ScaleTargetRef `json:"scaleTargetRef,omitempty"`
+	// ScaleTargetRefs are the targets scaled down and woken up together, the first one takes the place of scaleTargetRef.
+	// The requests are held in the resolver until all the required targets are ready.
+	// +kubebuilder:validation:MinItems=1
+	ScaleTargetRefs []ScaleTarget `json:"scaleTargetRefs,omitempty"`
 	// +kubebuilder:validation:Required
 	Service string `json:"service,omitempty"`
 	// +kubebuilder:validation:Minimum=1
 	MinTargetReplicas int32 `json:"minTargetReplicas,omitempty" default:"1"`
+	// IdleReplicas is the number of replicas the target is scaled down to when the triggers are idle, it must be lower than minTargetReplicas.
+	// A target found below it is scaled up to it, and the requests are proxied through the resolver while a target has no replicas.
+	// +kubebuilder:validation:Minimum=0
+	IdleReplicas int32 `json:"idleReplicas,omitempty"`
+	// MaxWakeReplicas is the most replicas the target is scaled up to when it is woken up by queued requests,
+	// it defaults to minTargetReplicas
+	// +kubebuilder:validation:Minimum=1
+	MaxWakeReplicas int32 `json:"maxWakeReplicas,omitempty"`
+	// WakeRequestsPerReplica is the number of queued requests each replica is woken up for, up to maxWakeReplicas
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	WakeRequestsPerReplica int32 `json:"wakeRequestsPerReplica,omitempty"`
+	// WakeReplicasPolicy decides the replicas the target is woken up with: min for minTargetReplicas,
+	// previous for the replicas it had before it was scaled down, or max for the most of the two
+	// +kubebuilder:validation:Enum=min;previous;max
+	// +kubebuilder:default=min
+	WakeReplicasPolicy string `json:"wakeReplicasPolicy,omitempty"`
 	// This is the cooldown period in seconds
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=604800
 	// +kubebuilder:default=900
-	CooldownPeriod int32           `json:"cooldownPeriod,omitempty"`
-	Triggers       []ScaleTrigger  `json:"triggers,omitempty"`
-	Autoscaler     *AutoscalerSpec `json:"autoscaler,omitempty"`
+	CooldownPeriod int32          `json:"cooldownPeriod,omitempty"`
+	Triggers       []ScaleTrigger `json:"triggers,omitempty"`
+	// TriggerPolicy decides when the triggers allow scaling to zero.
+	// It is either all, any or a CEL expression over the idle state of the named triggers,
+	// e.g. (triggers.requests && triggers.queue) || triggers.nightly
+	// +kubebuilder:default=all
+	TriggerPolicy string `json:"triggerPolicy,omitempty"`
+	// MinIdleEvaluations is the number of consecutive evaluations the triggers must be idle for before scaling to zero
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	MinIdleEvaluations int32 `json:"minIdleEvaluations,omitempty"`
+	// PollingInterval is how often the triggers are evaluated in seconds, it defaults to the polling interval of the operator
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	PollingInterval int32           `json:"pollingInterval,omitempty"`
+	Autoscaler      *AutoscalerSpec `json:"autoscaler,omitempty"`
+	// DependsOn are the ElastiServices this service needs to serve its requests. They are woken up before it,
+	// its requests are held in the resolver until they are in serve mode, and they are kept awake while it is awake.
+	DependsOn []ElastiServiceReference `json:"dependsOn,omitempty"`
+}
+
+// ElastiServiceReference refers to another ElastiService
+type ElastiServiceReference struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Namespace of the ElastiService, it defaults to the namespace of the referring ElastiService
+	Namespace string `json:"namespace,omitempty"`
 }
 
 type ScaleTargetRef struct {
-	// +kubebuilder:validation:Enum=apps/v1;argoproj.io/v1alpha1
-	APIVersion string `json:"apiVersion,omitempty"`
-	// +kubebuilder:validation:Enum=deployments;rollouts
-	Kind string `json:"kind,omitempty"`
-	Name string `json:"name,omitempty"`
+	// APIVersion is the group version of the target, e.g. apps/v1
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?v[0-9]+((alpha|beta)[0-9]+)?$`
+	APIVersion string `json:"apiVersion"`
+	// Kind is the kind or the resource of the target, e.g. Deployment, rollouts, statefulsets or any resource with
+	// a scale subresource. It is resolved to its resource with the RESTMapper.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9]*$`
+	Kind string `json:"kind"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// ReadyReplicasPath is the JSONPath of the ready replicas of the target, defaults to .status.readyReplicas.
+	// A boolean or a "True" condition status counts as one ready replica.
+	ReadyReplicasPath string `json:"readyReplicasPath,omitempty"`
+}
+
+// ScaleTarget is a target scaled together with the other targets of the ElastiService
+type ScaleTarget struct {
+	ScaleTargetRef `json:",inline"`
+	// MinReplicas is the number of replicas the target is woken up with, it defaults to minTargetReplicas
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	// Optional targets are scaled with the others, but the requests aren't held in the resolver until they are ready
+	Optional bool `json:"optional,omitempty"`
+}
+
+// ScaleTargets returns all the targets of the ElastiService, scaleTargetRef first when it is set,
+// with their minReplicas defaulted to minTargetReplicas
+func (s *ElastiServiceSpec) ScaleTargets() []ScaleTarget {
+	targets := make([]ScaleTarget, 0, len(s.ScaleTargetRefs)+1)
+	if s.ScaleTargetRef != nil {
+		targets = append(targets, ScaleTarget{ScaleTargetRef: *s.ScaleTargetRef})
+	}
+	targets = append(targets, s.ScaleTargetRefs...)
+	for i := range targets {
+		if targets[i].MinReplicas == 0 {
+			targets[i].MinReplicas = s.MinTargetReplicas
+		}
+	}
+	return targets
 }
 
 // ElastiServiceStatus defines the observed state of ElastiService
@@ -63,6 +171,36 @@ type ElastiServiceStatus struct {
 	LastReconciledTime metav1.Time  `json:"lastReconciledTime,omitempty"`
 	LastScaledUpTime   *metav1.Time `json:"lastScaledUpTime,omitempty"`
 	Mode               string       `json:"mode,omitempty"`
+	// TriggerEvaluation is the outcome of the last evaluation of the triggers
+	TriggerEvaluation *TriggerEvaluation `json:"triggerEvaluation,omitempty"`
+	// ConsecutiveIdleEvaluations is the number of consecutive evaluations the triggers have been idle for,
+	// up to minIdleEvaluations
+	ConsecutiveIdleEvaluations int32 `json:"consecutiveIdleEvaluations,omitempty"`
+	// ReplicasBeforeIdle is the number of replicas the target had before it was last scaled down to its idle replicas
+	ReplicasBeforeIdle int32 `json:"replicasBeforeIdle,omitempty"`
+	// Conditions report the state of the ElastiService, e.g. whether its triggers are valid
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+type TriggerEvaluation struct {
+	// LastTransitionTime is the last time the outcome of the evaluation changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	Policy             string      `json:"policy,omitempty"`
+	// ScaleToZero is the result of the policy
+	ScaleToZero bool `json:"scaleToZero"`
+	// Direction is the resulting scale direction: scaleup, scaledown or noscale
+	Direction string          `json:"direction,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Triggers  []TriggerResult `json:"triggers,omitempty"`
+}
+
+type TriggerResult struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=idle;active;unhealthy
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -87,7 +225,13 @@ type ElastiServiceList struct {
 }
 
 type ScaleTrigger struct {
-	// +kubebuilder:validation:Enum=prometheus
+	// Name identifies the trigger in the triggerPolicy, it defaults to the type of the trigger,
+	// followed by its index when several triggers share the type, e.g. prometheus_1. Dashes in the type are
+	// replaced with underscores, e.g. metrics_api
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	Name string `json:"name,omitempty"`
+	// Type is one of the trigger types registered in the operator, it is validated with the metadata
+	// and reported in the TriggersValid condition
 	Type string `json:"type"`
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Schemaless
@@ -22,6 +22,7 @@ package v1alpha1
 
 import (
 	"encoding/json"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -99,10 +100,34 @@ func (in *ElastiServiceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElastiServiceReference) DeepCopyInto(out *ElastiServiceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElastiServiceReference.
+func (in *ElastiServiceReference) DeepCopy() *ElastiServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ElastiServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElastiServiceSpec) DeepCopyInto(out *ElastiServiceSpec) {
 	*out = *in
-	out.ScaleTargetRef = in.ScaleTargetRef
+	if in.ScaleTargetRef != nil {
+		in, out := &in.ScaleTargetRef, &out.ScaleTargetRef
+		*out = new(ScaleTargetRef)
+		**out = **in
+	}
+	if in.ScaleTargetRefs != nil {
+		in, out := &in.ScaleTargetRefs, &out.ScaleTargetRefs
+		*out = make([]ScaleTarget, len(*in))
+		copy(*out, *in)
+	}
 	if in.Triggers != nil {
 		in, out := &in.Triggers, &out.Triggers
 		*out = make([]ScaleTrigger, len(*in))
@@ -115,6 +140,11 @@ func (in *ElastiServiceSpec) DeepCopyInto(out *ElastiServiceSpec) {
 		*out = new(AutoscalerSpec)
 		**out = **in
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]ElastiServiceReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElastiServiceSpec.
@@ -135,6 +165,18 @@ func (in *ElastiServiceStatus) DeepCopyInto(out *ElastiServiceStatus) {
 		in, out := &in.LastScaledUpTime, &out.LastScaledUpTime
 		*out = (*in).DeepCopy()
 	}
+	if in.TriggerEvaluation != nil {
+		in, out := &in.TriggerEvaluation, &out.TriggerEvaluation
+		*out = new(TriggerEvaluation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElastiServiceStatus.
@@ -147,6 +189,22 @@ func (in *ElastiServiceStatus) DeepCopy() *ElastiServiceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleTarget) DeepCopyInto(out *ScaleTarget) {
+	*out = *in
+	out.ScaleTargetRef = in.ScaleTargetRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleTarget.
+func (in *ScaleTarget) DeepCopy() *ScaleTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScaleTargetRef) DeepCopyInto(out *ScaleTargetRef) {
 	*out = *in
@@ -181,3 +239,39 @@ func (in *ScaleTrigger) DeepCopy() *ScaleTrigger {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerEvaluation) DeepCopyInto(out *TriggerEvaluation) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]TriggerResult, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerEvaluation.
+func (in *TriggerEvaluation) DeepCopy() *TriggerEvaluation {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerEvaluation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerResult) DeepCopyInto(out *TriggerResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerResult.
+func (in *TriggerResult) DeepCopy() *TriggerResult {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerResult)
+	in.DeepCopyInto(out)
+	return out
+}
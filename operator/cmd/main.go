@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -42,6 +43,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -68,6 +70,7 @@ func init() {
 
 const (
 	elastiServerPort = ":8013"
+	otlpGRPCPort     = ":4317"
 )
 
 func main() {
@@ -172,7 +175,8 @@ func mainWithError() error {
 	defer informerManager.Stop()
 
 	// Initiate and start the shared scaleHandler
-	scaleHandler := scaling.NewScaleHandler(zapLogger, mgr.GetConfig(), watchNamespace, mgr.GetEventRecorderFor("elasti-operator"))
+	eventRecorder := mgr.GetEventRecorderFor("elasti-operator")
+	scaleHandler := scaling.NewScaleHandler(zapLogger, mgr.GetConfig(), watchNamespace, eventRecorder)
 
 	// Set up the ElastiService controller
 	reconciler := &controller.ElastiServiceReconciler{
@@ -181,6 +185,7 @@ func mainWithError() error {
 		Logger:          zapLogger,
 		InformerManager: informerManager,
 		ScaleHandler:    scaleHandler,
+		Recorder:        eventRecorder,
 	}
 
 	if err = reconciler.SetupWithManager(mgr, watchNamespace); err != nil {
@@ -189,9 +194,19 @@ func mainWithError() error {
 		return fmt.Errorf("main: %w", err)
 	}
 
+	// The workloads pushing metrics are authenticated with the token of their service account
+	kClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create kubernetes client")
+		sentry.CaptureException(err)
+		return fmt.Errorf("main: %w", err)
+	}
+	authenticator := elastiserver.NewAuthenticator(kClient.AuthenticationV1().TokenReviews(),
+		strings.Split(os.Getenv("TRUSTED_SERVICE_ACCOUNTS"), ","))
+
 	// Start the elasti server
-	eServer := elastiserver.NewServer(zapLogger, scaleHandler, 30*time.Second)
-	errChan := make(chan error, 1)
+	eServer := elastiserver.NewServer(zapLogger, scaleHandler, authenticator, 30*time.Second)
+	errChan := make(chan error, 2)
 	go func() {
 		if err := eServer.Start(elastiServerPort); err != nil {
 			setupLog.Error(err, "elasti server failed to start")
@@ -199,6 +214,13 @@ func mainWithError() error {
 			errChan <- fmt.Errorf("elasti server: %w", err)
 		}
 	}()
+	go func() {
+		if err := eServer.StartOTLPReceiver(otlpGRPCPort); err != nil {
+			setupLog.Error(err, "OTLP receiver failed to start")
+			sentry.CaptureException(err)
+			errChan <- fmt.Errorf("OTLP receiver: %w", err)
+		}
+	}()
 
 	// Add error channel check before manager start
 	select {
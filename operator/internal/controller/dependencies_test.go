@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/truefoundry/elasti/pkg/values"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kRuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"truefoundry/elasti/operator/api/v1alpha1"
+)
+
+func newDependentElastiService(name, mode string, dependsOn ...string) *v1alpha1.ElastiService {
+	es := &v1alpha1.ElastiService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		Spec: v1alpha1.ElastiServiceSpec{
+			Service:        name,
+			ScaleTargetRef: &v1alpha1.ScaleTargetRef{APIVersion: "apps/v1", Kind: "deployments", Name: name},
+		},
+		Status: v1alpha1.ElastiServiceStatus{Mode: mode},
+	}
+	for _, dependency := range dependsOn {
+		es.Spec.DependsOn = append(es.Spec.DependsOn, v1alpha1.ElastiServiceReference{Name: dependency})
+	}
+	return es
+}
+
+// newDependenciesReconciler returns a reconciler whose client serves the ElastiServices from a cache indexed by their dependencies
+func newDependenciesReconciler(t *testing.T, services ...*v1alpha1.ElastiService) (*ElastiServiceReconciler, *record.FakeRecorder) {
+	scheme := kRuntime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	objects := make([]client.Object, 0, len(services))
+	for _, es := range services {
+		objects = append(objects, es)
+	}
+	recorder := record.NewFakeRecorder(10)
+	return &ElastiServiceReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objects...).
+			WithStatusSubresource(&v1alpha1.ElastiService{}).
+			WithIndex(&v1alpha1.ElastiService{}, dependsOnField, dependsOnIndex).
+			Build(),
+		Scheme:   scheme,
+		Logger:   zap.NewNop(),
+		Recorder: recorder,
+	}, recorder
+}
+
+func TestCheckDependencies(t *testing.T) {
+	tests := []struct {
+		name        string
+		services    []*v1alpha1.ElastiService
+		expectEvent string
+	}{
+		{
+			name: "Valid dependencies",
+			services: []*v1alpha1.ElastiService{
+				newDependentElastiService("frontend", values.ProxyMode, "api"),
+				newDependentElastiService("api", values.ProxyMode, "cache"),
+				newDependentElastiService("cache", values.ProxyMode),
+			},
+		},
+		{
+			name: "Cycle",
+			services: []*v1alpha1.ElastiService{
+				newDependentElastiService("frontend", values.ProxyMode, "api"),
+				newDependentElastiService("api", values.ProxyMode, "cache"),
+				newDependentElastiService("cache", values.ProxyMode, "frontend"),
+			},
+			expectEvent: "dependency cycle: shop/frontend -> shop/api -> shop/cache -> shop/frontend",
+		},
+		{
+			name: "Missing dependency",
+			services: []*v1alpha1.ElastiService{
+				newDependentElastiService("frontend", values.ProxyMode, "api"),
+			},
+			expectEvent: "shop/api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, recorder := newDependenciesReconciler(t, tt.services...)
+			r.checkDependencies(context.Background(), tt.services[0])
+
+			select {
+			case event := <-recorder.Events:
+				if tt.expectEvent == "" {
+					t.Fatalf("expected no event, got %s", event)
+				}
+				if !strings.HasPrefix(event, "Warning InvalidDependencies") || !strings.Contains(event, tt.expectEvent) {
+					t.Errorf("expected an InvalidDependencies event with %q, got %s", tt.expectEvent, event)
+				}
+			default:
+				if tt.expectEvent != "" {
+					t.Fatalf("expected an InvalidDependencies event with %q", tt.expectEvent)
+				}
+			}
+		})
+	}
+}
+
+func TestDependenciesServing(t *testing.T) {
+	ctx := context.Background()
+	frontend := newDependentElastiService("frontend", values.ProxyMode, "api", "auth")
+	admin := newDependentElastiService("admin", values.ProxyMode, "api")
+	api := newDependentElastiService("api", values.ServeMode)
+	auth := newDependentElastiService("auth", values.ProxyMode)
+	r, _ := newDependenciesReconciler(t, frontend, admin, api, auth)
+
+	if r.dependenciesServing(ctx, frontend, types.NamespacedName{}) {
+		t.Error("expected the requests to be held until auth is in serve mode")
+	}
+	// auth was just switched to serve mode, before the cache caught up
+	if !r.dependenciesServing(ctx, frontend, types.NamespacedName{Namespace: "shop", Name: "auth"}) {
+		t.Error("expected the dependencies to be serving once auth was switched to serve mode")
+	}
+
+	// A cycle doesn't hold the requests forever
+	cyclic := newDependentElastiService("cyclic", values.ProxyMode, "cyclic")
+	r, recorder := newDependenciesReconciler(t, cyclic)
+	if !r.dependenciesServing(ctx, cyclic, types.NamespacedName{}) {
+		t.Error("expected invalid dependencies to be ignored")
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("expected the invalid dependencies to only be reported on reconcile, got %d events", len(recorder.Events))
+	}
+}
+
+func TestDependents(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newDependenciesReconciler(t,
+		newDependentElastiService("frontend", values.ProxyMode, "api", "auth"),
+		newDependentElastiService("admin", values.ProxyMode, "api"),
+		newDependentElastiService("api", values.ServeMode),
+	)
+
+	dependents, err := r.dependents(ctx, newDependentElastiService("api", values.ServeMode))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, dependent := range dependents {
+		names[dependent.Name] = true
+	}
+	if len(names) != 2 || !names["frontend"] || !names["admin"] {
+		t.Errorf("expected frontend and admin to depend on api, got %v", names)
+	}
+
+	dependents, err = r.dependents(ctx, newDependentElastiService("frontend", values.ProxyMode))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dependents) != 0 {
+		t.Errorf("expected nothing to depend on frontend, got %d dependents", len(dependents))
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/truefoundry/elasti/pkg/scaling"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	"truefoundry/elasti/operator/internal/crddirectory"
 	"truefoundry/elasti/operator/internal/informer"
@@ -35,6 +36,7 @@ type (
 		InformerManager    *informer.Manager
 		SwitchModeLocks    sync.Map
 		ScaleHandler       *scaling.ScaleHandler
+		Recorder           record.EventRecorder
 		InformerStartLocks sync.Map
 		ReconcileLocks     sync.Map
 	}
@@ -107,12 +109,24 @@ func (r *ElastiServiceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 	r.Logger.Info("Finalizer added to CRD", zap.String("es", req.String()))
 
+	// Invalid triggers are reported in the TriggersValid condition and aren't evaluated,
+	// but they don't block the reconciliation as the service still has to be woken up by traffic
+	triggersErr := scaling.ValidateTriggers(&es.Spec)
+	if triggersErr != nil {
+		r.Logger.Warn("Invalid triggers", zap.String("es", req.String()), zap.Error(triggersErr))
+	}
+	if err := r.updateTriggersCondition(ctx, es, triggersErr); err != nil {
+		r.Logger.Error("Failed to update TriggersValid condition", zap.String("es", req.String()), zap.Error(err))
+		return res, err
+	}
+	r.checkDependencies(ctx, es)
+
 	// Add watch for public service, so when the public service is modified, we can update the private service
-	if err := r.watchScaleTargetRef(ctx, es, req); err != nil {
-		r.Logger.Error("Failed to add watch for ScaleTargetRef", zap.String("es", req.String()), zap.Any("scaleTargetRef", es.Spec.ScaleTargetRef), zap.Error(err))
+	if err := r.watchScaleTargets(ctx, es, req); err != nil {
+		r.Logger.Error("Failed to add watch for ScaleTargetRef", zap.String("es", req.String()), zap.Any("scaleTargets", es.Spec.ScaleTargets()), zap.Error(err))
 		return res, err
 	}
-	r.Logger.Info("Watch added for ScaleTargetRef", zap.String("es", req.String()), zap.Any("scaleTargetRef", es.Spec.ScaleTargetRef))
+	r.Logger.Info("Watch added for ScaleTargetRef", zap.String("es", req.String()), zap.Any("scaleTargets", es.Spec.ScaleTargets()))
 
 	// We add the CRD details to service directory, so when elasti server received a request,
 	// we can find the right resource to scale up
@@ -127,6 +141,10 @@ func (r *ElastiServiceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 }
 
 func (r *ElastiServiceReconciler) SetupWithManager(mgr ctrl.Manager, watchNamespace string) error {
+	// The dependents of an ElastiService are listed from the cache of the manager by this index
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.ElastiService{}, dependsOnField, dependsOnIndex); err != nil {
+		return fmt.Errorf("SetupWithManager: failed to index dependencies: %w", err)
+	}
 	err := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.ElastiService{}).
 		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
@@ -114,7 +114,7 @@ var _ = Describe("ElastiService Controller", func() {
 				Spec: elastiv1alpha1.ElastiServiceSpec{
 					MinTargetReplicas: 1,
 					Service:           resourceName,
-					ScaleTargetRef: elastiv1alpha1.ScaleTargetRef{
+					ScaleTargetRef: &elastiv1alpha1.ScaleTargetRef{
 						APIVersion: "apps/v1",
 						Kind:       "deployments",
 						Name:       resourceName,
@@ -3,7 +3,6 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
 	"truefoundry/elasti/operator/api/v1alpha1"
 	"truefoundry/elasti/operator/internal/crddirectory"
@@ -11,11 +10,10 @@ import (
 	"truefoundry/elasti/operator/internal/prom"
 
 	"github.com/truefoundry/elasti/pkg/k8shelper"
-	"github.com/truefoundry/elasti/pkg/utils"
 	"github.com/truefoundry/elasti/pkg/values"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -63,6 +61,35 @@ func (r *ElastiServiceReconciler) updateCRDStatus(ctx context.Context, crdNamesp
 	return nil
 }
 
+// updateTriggersCondition sets the TriggersValid condition from the validation error of the triggers,
+// the status is only patched, and an event recorded, when the condition changes
+func (r *ElastiServiceReconciler) updateTriggersCondition(ctx context.Context, es *v1alpha1.ElastiService, validationErr error) error {
+	original := es.DeepCopy()
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionTriggersValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.ReasonTriggersValid,
+		Message:            "The triggers and the triggerPolicy are valid",
+		ObservedGeneration: es.Generation,
+	}
+	if validationErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1alpha1.ReasonInvalidTriggers
+		condition.Message = validationErr.Error()
+	}
+	if !meta.SetStatusCondition(&es.Status.Conditions, condition) {
+		return nil
+	}
+
+	if err := r.Status().Patch(ctx, es, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to patch TriggersValid condition: %w", err)
+	}
+	if validationErr != nil {
+		r.Recorder.Eventf(es, "Warning", v1alpha1.ReasonInvalidTriggers, "Invalid triggers, they won't be evaluated: %v", validationErr)
+	}
+	return nil
+}
+
 func (r *ElastiServiceReconciler) addCRDFinalizer(ctx context.Context, es *v1alpha1.ElastiService) error {
 	// If the CRD does not contain the finalizer, we add the finalizer
 	if !controllerutil.ContainsFinalizer(es, v1alpha1.ElastiServiceFinalizer) {
@@ -85,7 +112,9 @@ func (r *ElastiServiceReconciler) finalizeCRD(ctx context.Context, es *v1alpha1.
 		r.InformerManager.StopForCRD(req.Name)
 		r.Logger.Info("[Done] Informer stopped for CRD", zap.String("es", req.String()))
 		// Reset the informer start mutex, so if the ElastiService is recreated, we will need to reset the informer
-		r.resetMutexForInformer(r.getMutexKeyForTargetRef(req))
+		for _, target := range es.Spec.ScaleTargets() {
+			r.resetMutexForInformer(r.getMutexKeyForTargetRef(req, target.ScaleTargetRef))
+		}
 		r.resetMutexForInformer(r.getMutexKeyForPublicSVC(req))
 		r.Logger.Info("[Done] Informer mutex reset for ScaleTargetRef and PublicSVC", zap.String("es", req.String()))
 	}()
@@ -122,61 +151,62 @@ func (r *ElastiServiceReconciler) finalizeCRD(ctx context.Context, es *v1alpha1.
 	return nil
 }
 
-// watchScaleTargetRef checks if the ScaleTargetRef has changed, and if it has, stops the informer for the old ScaleTargetRef
-// Start the new informer for the new ScaleTargetRef
-func (r *ElastiServiceReconciler) watchScaleTargetRef(ctx context.Context, es *v1alpha1.ElastiService, req ctrl.Request) error {
-	if es.Spec.ScaleTargetRef.Name == "" ||
-		es.Spec.ScaleTargetRef.Kind == "" ||
-		es.Spec.ScaleTargetRef.APIVersion == "" {
-		return fmt.Errorf("scaleTargetRef is incomplete: %w", k8shelper.ErrNoScaleTargetFound)
+// watchScaleTargets stops the informers of the targets which were removed from the ElastiService,
+// and starts the informers of its targets which aren't watched yet
+func (r *ElastiServiceReconciler) watchScaleTargets(ctx context.Context, es *v1alpha1.ElastiService, req ctrl.Request) error {
+	targets := es.Spec.ScaleTargets()
+	if len(targets) == 0 {
+		return fmt.Errorf("scaleTargetRef is missing: %w", k8shelper.ErrNoScaleTargetFound)
+	}
+	for _, target := range targets {
+		if target.Name == "" || target.Kind == "" || target.APIVersion == "" {
+			return fmt.Errorf("scaleTargetRef is incomplete: %w", k8shelper.ErrNoScaleTargetFound)
+		}
 	}
 
 	svcNamespacedName := types.NamespacedName{Name: es.Spec.Service, Namespace: es.Namespace}
 	crd, found := crddirectory.GetCRD(svcNamespacedName.String())
 	if found {
-		if es.Spec.ScaleTargetRef.Name != crd.Spec.ScaleTargetRef.Name ||
-			es.Spec.ScaleTargetRef.Kind != crd.Spec.ScaleTargetRef.Kind ||
-			es.Spec.ScaleTargetRef.APIVersion != crd.Spec.ScaleTargetRef.APIVersion {
-			r.Logger.Debug("ScaleTargetRef has changed, stopping previous informer.", zap.String("es", req.String()), zap.Any("scaleTargetRef", es.Spec.ScaleTargetRef))
-			key := r.InformerManager.GetKey(informer.KeyParams{
-				Namespace:    req.Namespace,
-				CRDName:      req.Name,
-				ResourceName: crd.Spec.ScaleTargetRef.Name,
-				Resource:     strings.ToLower(crd.Spec.ScaleTargetRef.Kind),
-			})
-			err := r.InformerManager.StopInformer(key)
+		for _, previous := range crd.Spec.ScaleTargets() {
+			if containsScaleTarget(targets, previous.ScaleTargetRef) {
+				continue
+			}
+			r.Logger.Debug("ScaleTargetRef has been removed, stopping its informer.", zap.String("es", req.String()), zap.Any("scaleTargetRef", previous.ScaleTargetRef))
+			gvr, err := r.ScaleHandler.TargetResource(previous.ScaleTargetRef)
+			if err == nil {
+				err = r.InformerManager.StopInformer(r.getScaleTargetInformerKey(req, previous.Name, gvr.Resource))
+			}
 			if err != nil {
-				r.Logger.Error("Failed to stop informer for old scaleTargetRef", zap.String("es", req.String()), zap.Any("scaleTargetRef", es.Spec.ScaleTargetRef), zap.Error(err))
+				// The informer is stopped with the others of the ElastiService once it is deleted
+				r.Logger.Error("Failed to stop informer for old scaleTargetRef", zap.String("es", req.String()), zap.Any("scaleTargetRef", previous.ScaleTargetRef), zap.Error(err))
 			}
-			r.Logger.Debug("Resetting mutex for old scaleTargetRef informer", zap.Any("scaleTargetRef", es.Spec.ScaleTargetRef))
-			r.resetMutexForInformer(r.getMutexKeyForTargetRef(req))
+			r.Logger.Debug("Resetting mutex for old scaleTargetRef informer", zap.Any("scaleTargetRef", previous.ScaleTargetRef))
+			r.resetMutexForInformer(r.getMutexKeyForTargetRef(req, previous.ScaleTargetRef))
 		}
 	}
 
-	var informerErr error
-	r.getMutexForInformerStart(r.getMutexKeyForTargetRef(req)).Do(func() {
-		targetGroup, targetVersion, err := utils.ParseAPIVersion(es.Spec.ScaleTargetRef.APIVersion)
+	for _, target := range targets {
+		// The kind is resolved to its resource with the RESTMapper, like when the target is scaled
+		gvr, err := r.ScaleHandler.TargetResource(target.ScaleTargetRef)
 		if err != nil {
-			informerErr = fmt.Errorf("failed to parse API version: %w", err)
-			return
+			return fmt.Errorf("failed to resolve scaleTargetRef %s %s: %w", target.Kind, target.Name, err)
 		}
-		if err := r.InformerManager.Add(&informer.RequestWatch{
-			Req:               req,
-			ResourceName:      es.Spec.ScaleTargetRef.Name,
-			ResourceNamespace: req.Namespace,
-			GroupVersionResource: &schema.GroupVersionResource{
-				Group:    targetGroup,
-				Version:  targetVersion,
-				Resource: strings.ToLower(es.Spec.ScaleTargetRef.Kind),
-			},
-			Handlers: r.getScaleTargetRefChangeHandler(ctx, es, req),
-		}); err != nil {
-			informerErr = fmt.Errorf("failed to add scaledTargetRef Informer: %w", err)
-			return
+		var informerErr error
+		r.getMutexForInformerStart(r.getMutexKeyForTargetRef(req, target.ScaleTargetRef)).Do(func() {
+			if err := r.InformerManager.Add(&informer.RequestWatch{
+				Req:                  req,
+				ResourceName:         target.Name,
+				ResourceNamespace:    req.Namespace,
+				GroupVersionResource: &gvr,
+				Handlers:             r.getScaleTargetRefChangeHandler(ctx, req, target.ScaleTargetRef, r.getScaleTargetInformerKey(req, target.Name, gvr.Resource)),
+			}); err != nil {
+				informerErr = fmt.Errorf("failed to add scaledTargetRef Informer: %w", err)
+				return
+			}
+		})
+		if informerErr != nil {
+			return informerErr
 		}
-	})
-	if informerErr != nil {
-		return informerErr
 	}
 	return nil
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"truefoundry/elasti/operator/api/v1alpha1"
 	"truefoundry/elasti/operator/internal/crddirectory"
 
 	"github.com/truefoundry/elasti/pkg/k8shelper"
@@ -13,27 +12,22 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
-	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-func (r *ElastiServiceReconciler) handleTargetDeploymentChanges(ctx context.Context, obj interface{}, _ *v1alpha1.ElastiService, req ctrl.Request) error {
+// deploymentState returns the state of the target deployment
+func deploymentState(obj interface{}) (targetState, error) {
 	targetDeployment := &appsv1.Deployment{}
 	err := k8shelper.UnstructuredToResource(obj, targetDeployment)
 	if err != nil {
-		return fmt.Errorf("failed to convert unstructured to deployment: %w", err)
+		return targetStarting, fmt.Errorf("failed to convert unstructured to deployment: %w", err)
 	}
 	if targetDeployment.Status.Replicas == 0 {
-		r.Logger.Info("ScaleTargetRef Deployment has 0 replicas", zap.String("deployment_name", targetDeployment.Name), zap.String("es", req.String()))
-		if err := r.switchMode(ctx, req, values.ProxyMode); err != nil {
-			return fmt.Errorf("failed to switch mode: %w", err)
-		}
-	} else if targetDeployment.Status.ReadyReplicas > 0 {
-		r.Logger.Info("ScaleTargetRef Deployment has ready replicas", zap.String("deployment_name", targetDeployment.Name), zap.String("es", req.String()))
-		if err := r.switchMode(ctx, req, values.ServeMode); err != nil {
-			return fmt.Errorf("failed to switch mode: %w", err)
-		}
+		return targetScaledDown, nil
 	}
-	return nil
+	if targetDeployment.Status.ReadyReplicas > 0 {
+		return targetReady, nil
+	}
+	return targetStarting, nil
 }
 
 func (r *ElastiServiceReconciler) handleResolverChanges(ctx context.Context, obj interface{}) error {
@@ -2,12 +2,12 @@ package controller
 
 import (
 	"context"
-	"fmt"
 	"strings"
 	"sync"
 
 	"github.com/truefoundry/elasti/pkg/values"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 
@@ -35,8 +35,18 @@ func (r *ElastiServiceReconciler) getMutexKeyForPublicSVC(req ctrl.Request) stri
 	return req.String() + lockKeyPostfixForPublicSVC
 }
 
-func (r *ElastiServiceReconciler) getMutexKeyForTargetRef(req ctrl.Request) string {
-	return req.String() + lockKeyPostfixForTargetRef
+func (r *ElastiServiceReconciler) getMutexKeyForTargetRef(req ctrl.Request, ref v1alpha1.ScaleTargetRef) string {
+	return req.String() + lockKeyPostfixForTargetRef + "/" + strings.ToLower(ref.Kind) + "/" + ref.Name
+}
+
+// getScaleTargetInformerKey returns the key of the informer of the target, the resource is the one its kind resolves to
+func (r *ElastiServiceReconciler) getScaleTargetInformerKey(req ctrl.Request, name string, resource string) string {
+	return r.InformerManager.GetKey(informer.KeyParams{
+		Namespace:    req.Namespace,
+		CRDName:      req.Name,
+		ResourceName: name,
+		Resource:     resource,
+	})
 }
 func (r *ElastiServiceReconciler) getResolverChangeHandler(ctx context.Context) cache.ResourceEventHandlerFuncs {
 	return cache.ResourceEventHandlerFuncs{
@@ -105,37 +115,19 @@ func (r *ElastiServiceReconciler) getPublicServiceChangeHandler(ctx context.Cont
 	}
 }
 
-func (r *ElastiServiceReconciler) getScaleTargetRefChangeHandler(ctx context.Context, es *v1alpha1.ElastiService, req ctrl.Request) cache.ResourceEventHandlerFuncs {
-	key := r.InformerManager.GetKey(informer.KeyParams{
-		Namespace:    req.Namespace,
-		CRDName:      req.Name,
-		ResourceName: es.Spec.ScaleTargetRef.Kind,
-		Resource:     es.Spec.ScaleTargetRef.Name,
-	})
+func (r *ElastiServiceReconciler) getScaleTargetRefChangeHandler(ctx context.Context, req ctrl.Request, ref v1alpha1.ScaleTargetRef, key string) cache.ResourceEventHandlerFuncs {
 	return cache.ResourceEventHandlerFuncs{
-		UpdateFunc: func(_, newObj interface{}) {
+		UpdateFunc: func(_, _ interface{}) {
 			errStr := values.Success
-			err := r.handleScaleTargetRefChanges(ctx, newObj, es, req)
+			err := r.handleScaleTargetsChanges(ctx, req, types.NamespacedName{})
 			if err != nil {
 				errStr = err.Error()
 				r.Logger.Error("Failed to handle ScaleTargetRef changes", zap.Error(err))
 			} else {
-				r.Logger.Info("ScaleTargetRef updated", zap.String("es", req.String()), zap.String("scaleTargetRef", es.Spec.ScaleTargetRef.Name))
+				r.Logger.Info("ScaleTargetRef updated", zap.String("es", req.String()), zap.String("scaleTargetRef", ref.Name))
 			}
 
 			prom.InformerHandlerCounter.WithLabelValues(req.String(), key, errStr).Inc()
 		},
 	}
 }
-
-func (r *ElastiServiceReconciler) handleScaleTargetRefChanges(ctx context.Context, obj interface{}, es *v1alpha1.ElastiService, req ctrl.Request) error {
-	r.Logger.Info("ScaleTargetRef changes detected", zap.String("es", req.String()), zap.Any("scaleTargetRef", es.Spec.ScaleTargetRef))
-	switch strings.ToLower(es.Spec.ScaleTargetRef.Kind) {
-	case values.KindDeployments:
-		return r.handleTargetDeploymentChanges(ctx, obj, es, req)
-	case values.KindRollout:
-		return r.handleTargetRolloutChanges(ctx, obj, es, req)
-	default:
-		return fmt.Errorf("unsupported target kind: %s", es.Spec.ScaleTargetRef.Kind)
-	}
-}
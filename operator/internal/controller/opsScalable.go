@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/truefoundry/elasti/pkg/k8shelper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// scalableState returns the state of any target with a scale subresource.
+// The replicas are read from the scale subresource, and the ready replicas from the readyReplicasPath of the target.
+func (r *ElastiServiceReconciler) scalableState(ctx context.Context, obj interface{}, namespace string, ref v1alpha1.ScaleTargetRef) (targetState, error) {
+	target, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return targetStarting, fmt.Errorf("unexpected object type %T", obj)
+	}
+	replicas, err := r.ScaleHandler.TargetReplicas(ctx, namespace, ref)
+	if err != nil {
+		return targetStarting, fmt.Errorf("failed to get replicas of %s %s: %w", ref.Kind, target.GetName(), err)
+	}
+	if replicas == 0 {
+		return targetScaledDown, nil
+	}
+
+	ready, err := k8shelper.ReadyReplicas(target, ref.ReadyReplicasPath)
+	if err != nil {
+		return targetStarting, fmt.Errorf("failed to get ready replicas of %s %s: %w", ref.Kind, target.GetName(), err)
+	}
+	if ready > 0 {
+		return targetReady, nil
+	}
+	return targetStarting, nil
+}
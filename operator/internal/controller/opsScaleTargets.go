@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/truefoundry/elasti/pkg/scaling"
+	"github.com/truefoundry/elasti/pkg/values"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// targetState is the state of a scale target, which decides the mode of its ElastiService
+type targetState int
+
+// dependsOnField indexes the ElastiServices in the cache of the manager by the namespaced names of their dependencies
+const dependsOnField = "spec.dependsOn"
+
+const (
+	// targetStarting is a target which is scaled up, but isn't ready yet
+	targetStarting targetState = iota
+	// targetScaledDown is a target without replicas
+	targetScaledDown
+	// targetReady is a target ready to serve the requests
+	targetReady
+)
+
+// handleScaleTargetsChanges switches the mode of the ElastiService with the state of its required targets:
+// to proxy mode as soon as one of them is scaled down, and to serve mode once all of them are ready.
+// The targets are read from the caches of their informers, so a change of any target is judged against all the others.
+// served is a dependency which was just switched to serve mode, it is counted as serving before the cache catches up.
+func (r *ElastiServiceReconciler) handleScaleTargetsChanges(ctx context.Context, req ctrl.Request, served types.NamespacedName) error {
+	es, err := r.getCRD(ctx, req.NamespacedName)
+	if err != nil {
+		return err
+	}
+
+	scaledDown, ready := false, true
+	for _, target := range requiredScaleTargets(es.Spec.ScaleTargets()) {
+		gvr, err := r.ScaleHandler.TargetResource(target.ScaleTargetRef)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s %s: %w", target.Kind, target.Name, err)
+		}
+		obj, err := r.InformerManager.GetObject(r.getScaleTargetInformerKey(req, target.Name, gvr.Resource), req.Namespace, target.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get %s %s: %w", target.Kind, target.Name, err)
+		}
+		state, err := r.scaleTargetState(ctx, obj, es.Namespace, target.ScaleTargetRef, gvr.GroupResource())
+		if err != nil {
+			return fmt.Errorf("failed to get state of %s %s: %w", target.Kind, target.Name, err)
+		}
+		r.Logger.Debug("ScaleTargetRef state", zap.String("es", req.String()), zap.String("kind", target.Kind), zap.String("name", target.Name), zap.Int("state", int(state)))
+		scaledDown = scaledDown || state == targetScaledDown
+		ready = ready && state == targetReady
+	}
+
+	switch {
+	case scaledDown:
+		r.Logger.Info("ScaleTargetRef has 0 replicas", zap.String("es", req.String()))
+		if err := r.switchMode(ctx, req, values.ProxyMode); err != nil {
+			return fmt.Errorf("failed to switch mode: %w", err)
+		}
+	case ready:
+		// The requests are held in the resolver until the dependencies are able to serve them as well
+		if !r.dependenciesServing(ctx, es, served) {
+			r.Logger.Info("Waiting for the dependencies to be in serve mode", zap.String("es", req.String()))
+			return nil
+		}
+		r.Logger.Info("All the required ScaleTargetRefs are ready", zap.String("es", req.String()))
+		if err := r.switchMode(ctx, req, values.ServeMode); err != nil {
+			return fmt.Errorf("failed to switch mode: %w", err)
+		}
+		if es.Status.Mode != values.ServeMode {
+			r.releaseDependents(ctx, es)
+		}
+	}
+	return nil
+}
+
+// dependenciesServing reports whether all the direct dependencies of the ElastiService are in serve mode,
+// counting served as serving. Their own dependencies are in serve mode as well then, since they were held until them.
+// Invalid dependencies, e.g. a cycle, are reported when the ElastiService is reconciled and don't hold its requests forever.
+func (r *ElastiServiceReconciler) dependenciesServing(ctx context.Context, es *v1alpha1.ElastiService, served types.NamespacedName) bool {
+	if len(es.Spec.DependsOn) == 0 {
+		return true
+	}
+	if _, err := scaling.DependencyOrder(ctx, es, r.getElastiService); err != nil {
+		r.Logger.Warn("Ignoring invalid dependencies", zap.String("es", es.Namespace+"/"+es.Name), zap.Error(err))
+		return true
+	}
+	for _, ref := range es.Spec.DependsOn {
+		key := scaling.DependencyKey(es, ref)
+		if key == served {
+			continue
+		}
+		dependency, err := r.getCRD(ctx, key)
+		if err != nil || dependency.Status.Mode != values.ServeMode {
+			return false
+		}
+	}
+	return true
+}
+
+// releaseDependents re-evaluates the targets of the dependents of the ElastiService which just switched to serve mode,
+// so the dependents whose requests were held for it are switched to serve mode as well
+func (r *ElastiServiceReconciler) releaseDependents(ctx context.Context, es *v1alpha1.ElastiService) {
+	dependents, err := r.dependents(ctx, es)
+	if err != nil {
+		r.Logger.Error("Failed to get dependents", zap.String("es", es.Namespace+"/"+es.Name), zap.Error(err))
+		return
+	}
+	for _, dependent := range dependents {
+		if dependent.Status.Mode == values.ServeMode {
+			continue
+		}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: dependent.Namespace, Name: dependent.Name}}
+		if err := r.handleScaleTargetsChanges(ctx, req, types.NamespacedName{Namespace: es.Namespace, Name: es.Name}); err != nil {
+			r.Logger.Error("Failed to handle ScaleTargetRef changes of dependent", zap.String("es", req.String()), zap.Error(err))
+		}
+	}
+}
+
+// scaleTargetState returns the state of the target from the object watched by its informer,
+// the resource of the target is the one its kind resolves to
+func (r *ElastiServiceReconciler) scaleTargetState(ctx context.Context, obj interface{}, namespace string, ref v1alpha1.ScaleTargetRef, resource schema.GroupResource) (targetState, error) {
+	if ref.ReadyReplicasPath != "" {
+		return r.scalableState(ctx, obj, namespace, ref)
+	}
+	switch resource {
+	case schema.GroupResource{Group: "apps", Resource: values.KindDeployments}:
+		return deploymentState(obj)
+	case schema.GroupResource{Group: "argoproj.io", Resource: values.KindRollout}:
+		return rolloutState(obj)
+	case schema.GroupResource{Group: "apps", Resource: values.KindStatefulSets}:
+		return statefulSetState(obj)
+	default:
+		return r.scalableState(ctx, obj, namespace, ref)
+	}
+}
+
+// requiredScaleTargets returns the targets which aren't optional, or all of them when they all are
+func requiredScaleTargets(targets []v1alpha1.ScaleTarget) []v1alpha1.ScaleTarget {
+	var required []v1alpha1.ScaleTarget
+	for _, target := range targets {
+		if !target.Optional {
+			required = append(required, target)
+		}
+	}
+	if len(required) == 0 {
+		return targets
+	}
+	return required
+}
+
+// containsScaleTarget reports whether the target is one of the targets
+func containsScaleTarget(targets []v1alpha1.ScaleTarget, ref v1alpha1.ScaleTargetRef) bool {
+	for _, target := range targets {
+		if target.Name == ref.Name && target.Kind == ref.Kind && target.APIVersion == ref.APIVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDependencies reports invalid dependencies of the ElastiService, e.g. a cycle, in an InvalidDependencies event.
+// They don't block the reconciliation, the requests of the ElastiService just aren't held for its dependencies.
+func (r *ElastiServiceReconciler) checkDependencies(ctx context.Context, es *v1alpha1.ElastiService) {
+	if _, err := scaling.DependencyOrder(ctx, es, r.getElastiService); err != nil {
+		r.Logger.Warn("Invalid dependencies", zap.String("es", es.Namespace+"/"+es.Name), zap.Error(err))
+		r.Recorder.Eventf(es, "Warning", "InvalidDependencies", "Invalid dependencies: %v", err)
+	}
+}
+
+// getElastiService reads the ElastiService from the cache of the manager
+func (r *ElastiServiceReconciler) getElastiService(ctx context.Context, namespace, name string) (*v1alpha1.ElastiService, error) {
+	return r.getCRD(ctx, types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+// dependents lists the ElastiServices which directly depend on the ElastiService from the cache of the manager,
+// where they are indexed by their dependencies
+func (r *ElastiServiceReconciler) dependents(ctx context.Context, es *v1alpha1.ElastiService) ([]v1alpha1.ElastiService, error) {
+	key := types.NamespacedName{Namespace: es.Namespace, Name: es.Name}
+	list := &v1alpha1.ElastiServiceList{}
+	if err := r.List(ctx, list, client.MatchingFields{dependsOnField: key.String()}); err != nil {
+		return nil, fmt.Errorf("failed to list dependents of %s: %w", key, err)
+	}
+	return list.Items, nil
+}
+
+// dependsOnIndex returns the keys the ElastiService is indexed by in the dependsOnField index
+func dependsOnIndex(obj client.Object) []string {
+	es, ok := obj.(*v1alpha1.ElastiService)
+	if !ok {
+		return nil
+	}
+	return scaling.DependencyKeys(es)
+}
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/truefoundry/elasti/pkg/k8shelper"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// statefulSetState returns the state of the target statefulset.
+// The pods of a statefulset may depend on each other, e.g. with the default OrderedReady pod management each pod
+// is only started once the previous one is ready, so it is only ready once all of them are ready.
+func statefulSetState(obj interface{}) (targetState, error) {
+	targetStatefulSet := &appsv1.StatefulSet{}
+	err := k8shelper.UnstructuredToResource(obj, targetStatefulSet)
+	if err != nil {
+		return targetStarting, fmt.Errorf("failed to convert unstructured to statefulset: %w", err)
+	}
+	if targetStatefulSet.Status.Replicas == 0 {
+		return targetScaledDown, nil
+	}
+	if statefulSetReady(targetStatefulSet) {
+		return targetReady, nil
+	}
+	return targetStarting, nil
+}
+
+// statefulSetReady reports whether all the desired replicas of the statefulset are ready
+func statefulSetReady(statefulSet *appsv1.StatefulSet) bool {
+	desired := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desired = *statefulSet.Spec.Replicas
+	}
+	return desired > 0 && statefulSet.Status.ReadyReplicas >= desired
+}
@@ -27,6 +27,7 @@ import (
 	"truefoundry/elasti/operator/internal/crddirectory"
 	"truefoundry/elasti/operator/internal/informer"
 
+	"github.com/truefoundry/elasti/pkg/scaling"
 	uberZap "go.uber.org/zap"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -38,6 +39,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -120,6 +122,8 @@ var _ = BeforeSuite(func() {
 		Scheme:             k8sClient.Scheme(),
 		Logger:             uberZap.NewExample(),
 		InformerManager:    informerManager,
+		Recorder:           record.NewFakeRecorder(100),
+		ScaleHandler:       scaling.NewScaleHandler(uberZap.NewExample(), cfg, metav1.NamespaceAll, record.NewFakeRecorder(100)),
 		SwitchModeLocks:    sync.Map{},
 		InformerStartLocks: sync.Map{},
 		ReconcileLocks:     sync.Map{},
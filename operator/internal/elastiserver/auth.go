@@ -0,0 +1,157 @@
+package elastiserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+)
+
+const (
+	// tokenReviewCacheTTL is how long the outcome of the review of a token is reused for
+	tokenReviewCacheTTL = time.Minute
+	// tokenReviewCacheSize bounds the number of reviewed tokens kept in memory
+	tokenReviewCacheSize = 10000
+
+	serviceAccountUsernamePrefix = "system:serviceaccount:"
+)
+
+// errUnauthenticated is returned for a caller without a valid service account token
+var errUnauthenticated = errors.New("unauthenticated")
+
+// caller is a workload authenticated with the token of its service account
+type caller struct {
+	namespace      string
+	serviceAccount string
+	// trusted callers, like a collector pushing the metrics of all the namespaces, aren't limited to their namespace
+	trusted bool
+}
+
+// Authenticator authenticates the workloads pushing metrics and leases with the token of their service account,
+// reviewed by the API server. A workload can only push for the ElastiServices of its own namespace,
+// unless its service account is trusted.
+type Authenticator struct {
+	tokenReviews authenticationv1client.TokenReviewInterface
+	// trusted are the namespace/name of the service accounts allowed to push for any namespace
+	trusted map[string]bool
+	now     func() time.Time
+
+	mu sync.Mutex
+	// reviews caches the outcome of the reviews by the hash of the token,
+	// so a workload pushing often doesn't create a TokenReview for every push
+	reviews map[[sha256.Size]byte]tokenReview
+}
+
+type tokenReview struct {
+	caller    *caller
+	err       error
+	expiresAt time.Time
+}
+
+// NewAuthenticator creates an Authenticator trusting the service accounts, given as namespace/name
+func NewAuthenticator(tokenReviews authenticationv1client.TokenReviewInterface, trustedServiceAccounts []string) *Authenticator {
+	trusted := make(map[string]bool, len(trustedServiceAccounts))
+	for _, serviceAccount := range trustedServiceAccounts {
+		if serviceAccount = strings.TrimSpace(serviceAccount); serviceAccount != "" {
+			trusted[serviceAccount] = true
+		}
+	}
+	return &Authenticator{
+		tokenReviews: tokenReviews,
+		trusted:      trusted,
+		now:          time.Now,
+		reviews:      make(map[[sha256.Size]byte]tokenReview),
+	}
+}
+
+// authenticate returns the caller whose bearer token is in the Authorization header
+func (a *Authenticator) authenticate(ctx context.Context, authorization string) (*caller, error) {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("%w: the token of a service account is required as a bearer token", errUnauthenticated)
+	}
+	token = strings.TrimSpace(token)
+	key := sha256.Sum256([]byte(token))
+	now := a.now()
+
+	a.mu.Lock()
+	review, ok := a.reviews[key]
+	a.mu.Unlock()
+	if ok && now.Before(review.expiresAt) {
+		return review.caller, review.err
+	}
+
+	c, err := a.review(ctx, token)
+	if err != nil && !errors.Is(err, errUnauthenticated) {
+		// The API server couldn't review the token, it is reviewed again on the next call
+		return nil, err
+	}
+	a.store(key, tokenReview{caller: c, err: err, expiresAt: now.Add(tokenReviewCacheTTL)})
+	return c, err
+}
+
+func (a *Authenticator) review(ctx context.Context, token string) (*caller, error) {
+	review, err := a.tokenReviews.Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return nil, fmt.Errorf("%w: invalid token %s", errUnauthenticated, review.Status.Error)
+	}
+
+	serviceAccount, ok := strings.CutPrefix(review.Status.User.Username, serviceAccountUsernamePrefix)
+	namespace, name, found := strings.Cut(serviceAccount, ":")
+	if !ok || !found || namespace == "" || name == "" {
+		return nil, fmt.Errorf("%w: %s isn't a service account", errUnauthenticated, review.Status.User.Username)
+	}
+	return &caller{
+		namespace:      namespace,
+		serviceAccount: name,
+		trusted:        a.trusted[namespace+"/"+name],
+	}, nil
+}
+
+// store caches the review, the expired reviews are evicted once the cache is full
+func (a *Authenticator) store(key [sha256.Size]byte, review tokenReview) {
+	now := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.reviews) >= tokenReviewCacheSize {
+		for k, r := range a.reviews {
+			if !now.Before(r.expiresAt) {
+				delete(a.reviews, k)
+			}
+		}
+	}
+	if len(a.reviews) < tokenReviewCacheSize {
+		a.reviews[key] = review
+	}
+}
+
+// authenticateRequest returns the caller of the request, or writes the error response when it can't be authenticated
+func (s *Server) authenticateRequest(w http.ResponseWriter, req *http.Request) (*caller, bool) {
+	c, err := s.authenticator.authenticate(req.Context(), req.Header.Get("Authorization"))
+	switch {
+	case errors.Is(err, errUnauthenticated):
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	case err != nil:
+		s.logger.Error("Failed to authenticate request", zap.String("path", req.URL.Path), zap.Error(err))
+		http.Error(w, "Failed to authenticate request", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return c, true
+}
@@ -0,0 +1,138 @@
+package elastiserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// testTokens are the service accounts of the tokens known to newTestAuthenticator
+var testTokens = map[string]string{
+	"shop-token":      "system:serviceaccount:shop:checkout",
+	"collector-token": "system:serviceaccount:observability:collector",
+	"orders-token":    "system:serviceaccount:orders:api",
+	"user-token":      "jane",
+}
+
+// newTestAuthenticator returns an Authenticator reviewing testTokens, trusting the collector service account,
+// and the number of TokenReviews created
+func newTestAuthenticator() (*Authenticator, *int) {
+	reviews := 0
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "unavailable-token" {
+			return true, nil, errors.New("connection refused")
+		}
+		username, ok := testTokens[review.Spec.Token]
+		review.Status = authenticationv1.TokenReviewStatus{
+			Authenticated: ok,
+			User:          authenticationv1.UserInfo{Username: username},
+		}
+		return true, review, nil
+	})
+	return NewAuthenticator(clientset.AuthenticationV1().TokenReviews(), []string{"observability/collector"}), &reviews
+}
+
+func TestAuthenticatorAuthenticate(t *testing.T) {
+	tests := []struct {
+		name            string
+		authorization   string
+		expectCaller    *caller
+		expectErr       bool
+		expectUnauthErr bool
+	}{
+		{
+			name:          "Service account",
+			authorization: "Bearer shop-token",
+			expectCaller:  &caller{namespace: "shop", serviceAccount: "checkout"},
+		},
+		{
+			name:          "Trusted service account",
+			authorization: "Bearer collector-token",
+			expectCaller:  &caller{namespace: "observability", serviceAccount: "collector", trusted: true},
+		},
+		{
+			name:            "Missing token",
+			authorization:   "",
+			expectErr:       true,
+			expectUnauthErr: true,
+		},
+		{
+			name:            "Not a bearer token",
+			authorization:   "Basic c2hvcA==",
+			expectErr:       true,
+			expectUnauthErr: true,
+		},
+		{
+			name:            "Invalid token",
+			authorization:   "Bearer forged-token",
+			expectErr:       true,
+			expectUnauthErr: true,
+		},
+		{
+			name:            "Not a service account",
+			authorization:   "Bearer user-token",
+			expectErr:       true,
+			expectUnauthErr: true,
+		},
+		{
+			name:          "Review failed",
+			authorization: "Bearer unavailable-token",
+			expectErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator, _ := newTestAuthenticator()
+			c, err := authenticator.authenticate(context.Background(), tt.authorization)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("expected an error to be %t, got %v", tt.expectErr, err)
+			}
+			if errors.Is(err, errUnauthenticated) != tt.expectUnauthErr {
+				t.Fatalf("expected an unauthenticated error to be %t, got %v", tt.expectUnauthErr, err)
+			}
+			if tt.expectCaller != nil && (c == nil || *c != *tt.expectCaller) {
+				t.Errorf("expected caller %+v, got %+v", tt.expectCaller, c)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorCachesReviews(t *testing.T) {
+	authenticator, reviews := newTestAuthenticator()
+	now := time.Now()
+	authenticator.now = func() time.Time { return now }
+
+	for _, token := range []string{"shop-token", "shop-token", "forged-token", "forged-token"} {
+		_, _ = authenticator.authenticate(context.Background(), "Bearer "+token)
+	}
+	if *reviews != 2 {
+		t.Errorf("expected the reviews to be reused, got %d reviews", *reviews)
+	}
+
+	// A failed review isn't cached
+	for range 2 {
+		_, _ = authenticator.authenticate(context.Background(), "Bearer unavailable-token")
+	}
+	if *reviews != 4 {
+		t.Errorf("expected the failed reviews to be retried, got %d reviews", *reviews)
+	}
+
+	// The review expires after tokenReviewCacheTTL
+	now = now.Add(tokenReviewCacheTTL)
+	if _, err := authenticator.authenticate(context.Background(), "Bearer shop-token"); err != nil {
+		t.Fatal(err)
+	}
+	if *reviews != 5 {
+		t.Errorf("expected the token to be reviewed again, got %d reviews", *reviews)
+	}
+}
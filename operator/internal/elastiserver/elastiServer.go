@@ -14,8 +14,10 @@ import (
 
 	sentryhttp "github.com/getsentry/sentry-go/http"
 	"github.com/truefoundry/elasti/pkg/scaling"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"truefoundry/elasti/operator/api/v1alpha1"
 	"truefoundry/elasti/operator/internal/crddirectory"
 	"truefoundry/elasti/operator/internal/prom"
 
@@ -35,16 +37,19 @@ type (
 	Server struct {
 		logger       *zap.Logger
 		scaleHandler *scaling.ScaleHandler
+		// authenticator authenticates the workloads pushing metrics over OTLP and posting leases
+		authenticator *Authenticator
 		// rescaleDuration is the duration to wait before checking to rescaling the target
 		rescaleDuration time.Duration
 	}
 )
 
-func NewServer(logger *zap.Logger, scaleHandler *scaling.ScaleHandler, rescaleDuration time.Duration) *Server {
+func NewServer(logger *zap.Logger, scaleHandler *scaling.ScaleHandler, authenticator *Authenticator, rescaleDuration time.Duration) *Server {
 	// Get Ops client
 	return &Server{
-		logger:       logger.Named("elastiServer"),
-		scaleHandler: scaleHandler,
+		logger:        logger.Named("elastiServer"),
+		scaleHandler:  scaleHandler,
+		authenticator: authenticator,
 		// rescaleDuration is the duration to wait before checking to rescaling the target
 		rescaleDuration: rescaleDuration,
 	}
@@ -56,6 +61,8 @@ func (s *Server) Start(port string) error {
 	sentryHandler := sentryhttp.New(sentryhttp.Options{})
 	mux.Handle("/metrics", sentryHandler.Handle(promhttp.Handler()))
 	mux.Handle("/informer/incoming-request", sentryHandler.HandleFunc(s.resolverReqHandler))
+	mux.Handle("/v1/metrics", sentryHandler.HandleFunc(s.otlpMetricsHandler))
+	mux.Handle("/leases", sentryHandler.HandleFunc(s.leaseHandler))
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%s", strings.TrimPrefix(port, ":")),
@@ -132,7 +139,7 @@ func (s *Server) resolverReqHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if err = s.scaleTargetForService(req.Context(), body.Svc, body.Namespace); err != nil {
+	if err = s.scaleTargetForService(req.Context(), body.Svc, body.Namespace, body.Count); err != nil {
 		s.logger.Error("Failed to scale target",
 			zap.Error(err),
 			zap.String("service", body.Svc),
@@ -145,7 +152,7 @@ func (s *Server) resolverReqHandler(w http.ResponseWriter, req *http.Request) {
 		zap.String("namespace", body.Namespace))
 }
 
-func (s *Server) scaleTargetForService(ctx context.Context, serviceName, namespace string) error {
+func (s *Server) scaleTargetForService(ctx context.Context, serviceName, namespace string, queuedRequests int) error {
 	namespacedName := types.NamespacedName{Namespace: namespace, Name: serviceName}
 
 	defer s.logger.Debug("Scale target lock released", zap.String("service", namespacedName.String()))
@@ -155,22 +162,39 @@ func (s *Server) scaleTargetForService(ctx context.Context, serviceName, namespa
 	if !found {
 		return fmt.Errorf("scaleTargetForService - error: failed to get CRD details from directory, namespacedName: %s", namespacedName)
 	}
+	// The dependencies are woken up first, their failures don't keep the service itself asleep
+	es := &v1alpha1.ElastiService{ObjectMeta: metav1.ObjectMeta{Name: crd.CRDName, Namespace: namespace}, Spec: crd.Spec}
+	if err := s.scaleHandler.WakeDependencies(ctx, es); err != nil {
+		s.logger.Error("failed to wake up dependencies", zap.String("service", namespacedName.String()), zap.Error(err))
+	}
+
 	if err := s.scaleHandler.UpdateLastScaledUpTime(ctx, crd.CRDName, namespace); err != nil {
 		s.logger.Error("failed to update LastScaledUpTime", zap.String("service", namespacedName.String()), zap.Error(err))
 	}
 
 	// Unpause the Keda ScaledObject if it's paused
 	if crd.Spec.Autoscaler != nil && strings.ToLower(crd.Spec.Autoscaler.Type) == "keda" {
-		if err := s.scaleHandler.UpdateKedaScaledObjectPausedState(ctx, crd.Spec.Autoscaler.Name, namespace, false); err != nil {
+		if err := s.scaleHandler.UpdateKedaScaledObjectPausedState(ctx, crd.Spec.Autoscaler.Name, namespace, false, 0); err != nil {
 			return fmt.Errorf("failed to update Keda ScaledObject for service %s: %w", namespacedName.String(), err)
 		}
 	}
 
-	if err := s.scaleHandler.ScaleTargetFromZero(ctx, namespacedName, crd.Spec.ScaleTargetRef.Kind, crd.Spec.ScaleTargetRef.Name, crd.Spec.MinTargetReplicas, crd.CRDName); err != nil {
-		prom.TargetScaleCounter.WithLabelValues(serviceName, namespace, crd.Spec.ScaleTargetRef.Kind+"-"+crd.Spec.ScaleTargetRef.Name, err.Error()).Inc()
-		return fmt.Errorf("scaleTargetForService - error: %w, targetRefKind: %s, targetRefName: %s", err, crd.Spec.ScaleTargetRef.Kind, crd.Spec.ScaleTargetRef.Name)
+	targets := targetsLabel(crd.Spec)
+	if err := s.scaleHandler.ScaleTargetsFromZero(ctx, namespacedName, crd.CRDName, crd.Spec, crd.Status, queuedRequests); err != nil {
+		prom.TargetScaleCounter.WithLabelValues(serviceName, namespace, targets, err.Error()).Inc()
+		return fmt.Errorf("scaleTargetForService - error: %w, targets: %s", err, targets)
 	}
-	prom.TargetScaleCounter.WithLabelValues(serviceName, namespace, crd.Spec.ScaleTargetRef.Kind+"-"+crd.Spec.ScaleTargetRef.Name, "success").Inc()
+	prom.TargetScaleCounter.WithLabelValues(serviceName, namespace, targets, "success").Inc()
 
 	return nil
 }
+
+// targetsLabel returns the kind-name of the targets of the ElastiService, comma separated
+func targetsLabel(spec v1alpha1.ElastiServiceSpec) string {
+	targets := spec.ScaleTargets()
+	labels := make([]string, 0, len(targets))
+	for _, target := range targets {
+		labels = append(labels, target.Kind+"-"+target.Name)
+	}
+	return strings.Join(labels, ",")
+}
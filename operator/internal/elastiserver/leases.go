@@ -0,0 +1,93 @@
+package elastiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"truefoundry/elasti/operator/internal/crddirectory"
+
+	"github.com/truefoundry/elasti/pkg/messages"
+	"github.com/truefoundry/elasti/pkg/scaling/scalers"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// leaseHandler records the leases posted by the workloads, the heartbeat triggers keep
+// their ElastiService awake while any of its leases is live. A workload can only post leases
+// for the services of its own namespace, unless its service account is trusted.
+func (s *Server) leaseHandler(w http.ResponseWriter, req *http.Request) {
+	defer func() {
+		if err := req.Body.Close(); err != nil {
+			s.logger.Error("Failed to close request body", zap.Error(err))
+		}
+	}()
+
+	if req.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+	c, ok := s.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	var body messages.Lease
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		s.logger.Error("Failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Svc == "" || body.Holder == "" {
+		http.Error(w, "svc and holder are required", http.StatusBadRequest)
+		return
+	}
+	if body.Namespace == "" {
+		body.Namespace = c.namespace
+	}
+	if !c.trusted && body.Namespace != c.namespace {
+		http.Error(w, fmt.Sprintf("Service account %s/%s can only post leases in namespace %s", c.namespace, c.serviceAccount, c.namespace), http.StatusForbidden)
+		return
+	}
+
+	namespacedName := types.NamespacedName{Namespace: body.Namespace, Name: body.Svc}
+	crd, found := crddirectory.GetCRD(namespacedName.String())
+	if !found {
+		http.Error(w, fmt.Sprintf("No ElastiService found for service %s", namespacedName), http.StatusNotFound)
+		return
+	}
+
+	until := time.Now().Add(time.Duration(body.TTLSeconds) * time.Second)
+	if body.Until != nil {
+		until = *body.Until
+	}
+	if err := s.scaleHandler.LeaseStore().Acquire(body.Namespace, crd.CRDName, body.Holder, until); errors.Is(err, scalers.ErrTooManyLeases) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.logger.Debug("Lease received",
+		zap.String("service", namespacedName.String()),
+		zap.String("holder", body.Holder),
+		zap.Time("until", until))
+
+	response := Response{
+		Message: "Lease recorded successfully!",
+	}
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		s.logger.Error("Failed to marshal response", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(jsonResponse); err != nil {
+		s.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
@@ -0,0 +1,152 @@
+package elastiserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"truefoundry/elasti/operator/internal/crddirectory"
+
+	"github.com/truefoundry/elasti/pkg/scaling"
+	"github.com/truefoundry/elasti/pkg/scaling/scalers"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+)
+
+func TestLeaseHandler(t *testing.T) {
+	logger := zap.NewNop()
+	crddirectory.InitDirectory(logger)
+	crddirectory.AddCRD("shop/checkout", &crddirectory.CRDDetails{CRDName: "checkout-elasti"})
+	defer crddirectory.RemoveCRD("shop/checkout")
+
+	scaleHandler := scaling.NewScaleHandler(logger, &rest.Config{Host: "http://127.0.0.1:0"}, "", nil)
+	authenticator, _ := newTestAuthenticator()
+	server := NewServer(logger, scaleHandler, authenticator, time.Second)
+
+	tests := []struct {
+		name         string
+		method       string
+		token        string
+		body         string
+		expectStatus int
+		expectLive   bool
+	}{
+		{
+			name:         "Lease with a TTL",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			body:         `{"svc": "checkout", "namespace": "shop", "holder": "session-1", "ttlSeconds": 600}`,
+			expectStatus: http.StatusOK,
+			expectLive:   true,
+		},
+		{
+			name:         "Lease released",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			body:         `{"svc": "checkout", "namespace": "shop", "holder": "session-1", "ttlSeconds": 0}`,
+			expectStatus: http.StatusOK,
+			expectLive:   false,
+		},
+		{
+			name:         "Lease until a time",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			body:         `{"svc": "checkout", "namespace": "shop", "holder": "export-1", "until": "` + time.Now().Add(30*time.Minute).Format(time.RFC3339) + `"}`,
+			expectStatus: http.StatusOK,
+			expectLive:   true,
+		},
+		{
+			name:         "Lease too long",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			body:         `{"svc": "checkout", "namespace": "shop", "holder": "export-2", "ttlSeconds": 172800}`,
+			expectStatus: http.StatusBadRequest,
+			expectLive:   true,
+		},
+		{
+			name:         "Lease in the namespace of the service account",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			body:         `{"svc": "checkout", "holder": "session-2", "ttlSeconds": 600}`,
+			expectStatus: http.StatusOK,
+			expectLive:   true,
+		},
+		{
+			name:         "Lease in another namespace",
+			method:       http.MethodPost,
+			token:        "orders-token",
+			body:         `{"svc": "checkout", "namespace": "shop", "holder": "session-3", "ttlSeconds": 0}`,
+			expectStatus: http.StatusForbidden,
+			expectLive:   true,
+		},
+		{
+			name:         "Trusted service account releasing a lease in any namespace",
+			method:       http.MethodPost,
+			token:        "collector-token",
+			body:         `{"svc": "checkout", "namespace": "shop", "holder": "session-2", "ttlSeconds": 0}`,
+			expectStatus: http.StatusOK,
+			expectLive:   true,
+		},
+		{
+			name:         "Missing token",
+			method:       http.MethodPost,
+			body:         `{"svc": "checkout", "namespace": "shop", "holder": "session-4", "ttlSeconds": 600}`,
+			expectStatus: http.StatusUnauthorized,
+			expectLive:   true,
+		},
+		{
+			name:         "Missing holder",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			body:         `{"svc": "checkout", "namespace": "shop", "ttlSeconds": 600}`,
+			expectStatus: http.StatusBadRequest,
+			expectLive:   true,
+		},
+		{
+			name:         "Unknown service",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			body:         `{"svc": "orders", "namespace": "shop", "holder": "session-1", "ttlSeconds": 600}`,
+			expectStatus: http.StatusNotFound,
+			expectLive:   true,
+		},
+		{
+			name:         "Invalid method",
+			method:       http.MethodGet,
+			token:        "shop-token",
+			expectStatus: http.StatusMethodNotAllowed,
+			expectLive:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/leases", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			recorder := httptest.NewRecorder()
+			server.leaseHandler(recorder, req)
+
+			if recorder.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectStatus, recorder.Code, recorder.Body.String())
+			}
+
+			// The heartbeat trigger wakes the service up while a lease is live
+			scaler, err := scalers.NewHeartbeatScaler(scaleHandler.LeaseStore(), "shop", "checkout-elasti", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			live, err := scaler.ShouldScaleFromZero(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if live != tt.expectLive {
+				t.Errorf("expected a live lease to be %t, got %t", tt.expectLive, live)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package elastiserver
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	// Registers the gzip compressor, which the OTLP exporters can be configured to use
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// otlpMaxRequestBytes limits the size of the OTLP requests, after decompression
+	otlpMaxRequestBytes = 8 << 20
+
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// otlpMetricsService receives the metrics pushed over OTLP/gRPC
+type otlpMetricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	server *Server
+}
+
+func (o *otlpMetricsService) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		authorization = md.Get("authorization")[0]
+	}
+	c, err := o.server.authenticator.authenticate(ctx, authorization)
+	switch {
+	case errors.Is(err, errUnauthenticated):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		o.server.logger.Error("Failed to authenticate OTLP export", zap.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to authenticate request")
+	}
+	return o.server.exportMetrics(c, req), nil
+}
+
+// StartOTLPReceiver starts the OTLP/gRPC receiver for the metrics used by the otlp triggers.
+// The OTLP/HTTP receiver is served by Start, on /v1/metrics.
+func (s *Server) StartOTLPReceiver(port string) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", strings.TrimPrefix(port, ":")))
+	if err != nil {
+		return fmt.Errorf("failed to listen for the OTLP receiver: %w", err)
+	}
+
+	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(otlpMaxRequestBytes))
+	colmetricspb.RegisterMetricsServiceServer(grpcServer, &otlpMetricsService{server: s})
+
+	// Graceful shutdown handling
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-quit
+		s.logger.Info("OTLP receiver is shutting down...")
+		grpcServer.GracefulStop()
+	}()
+
+	s.logger.Info("Starting OTLP receiver", zap.String("port", port))
+	if err := grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		s.logger.Error("Failed to start OTLP receiver", zap.Error(err))
+		return fmt.Errorf("failed to start OTLP receiver: %w", err)
+	}
+	return nil
+}
+
+// otlpMetricsHandler receives the metrics pushed over OTLP/HTTP, encoded as protobuf or JSON
+func (s *Server) otlpMetricsHandler(w http.ResponseWriter, req *http.Request) {
+	defer func() {
+		if err := req.Body.Close(); err != nil {
+			s.logger.Error("Failed to close request body", zap.Error(err))
+		}
+	}()
+
+	if req.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+	c, ok := s.authenticateRequest(w, req)
+	if !ok {
+		return
+	}
+	contentType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || (contentType != contentTypeProtobuf && contentType != contentTypeJSON) {
+		http.Error(w, "Unsupported content type, use application/x-protobuf or application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, req.Body, otlpMaxRequestBytes)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(body)
+		if err != nil {
+			http.Error(w, "Invalid gzip body", http.StatusBadRequest)
+			return
+		}
+		defer gzipReader.Close()
+		body = io.LimitReader(gzipReader, otlpMaxRequestBytes)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		s.logger.Error("Failed to read OTLP request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var exportRequest colmetricspb.ExportMetricsServiceRequest
+	if contentType == contentTypeJSON {
+		err = protojson.Unmarshal(data, &exportRequest)
+	} else {
+		err = proto.Unmarshal(data, &exportRequest)
+	}
+	if err != nil {
+		s.logger.Error("Failed to decode OTLP request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	exportResponse := s.exportMetrics(c, &exportRequest)
+	var response []byte
+	if contentType == contentTypeJSON {
+		response, err = protojson.Marshal(exportResponse)
+	} else {
+		response, err = proto.Marshal(exportResponse)
+	}
+	if err != nil {
+		s.logger.Error("Failed to marshal OTLP response", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(response); err != nil {
+		s.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
+
+// exportMetrics records the metrics pushed by the caller for the otlp triggers,
+// in its own namespace unless it is trusted to push for any namespace
+func (s *Server) exportMetrics(c *caller, req *colmetricspb.ExportMetricsServiceRequest) *colmetricspb.ExportMetricsServiceResponse {
+	callerNamespace := c.namespace
+	if c.trusted {
+		callerNamespace = ""
+	}
+
+	response := &colmetricspb.ExportMetricsServiceResponse{}
+	rejected, message := s.scaleHandler.MetricStore().ConsumeOTLP(callerNamespace, req.GetResourceMetrics())
+	if rejected > 0 {
+		s.logger.Debug("Rejected OTLP data points",
+			zap.String("serviceAccount", c.namespace+"/"+c.serviceAccount),
+			zap.Int64("rejected", rejected),
+			zap.String("reason", message))
+		response.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected,
+			ErrorMessage:       message,
+		}
+	}
+	return response
+}
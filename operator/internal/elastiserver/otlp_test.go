@@ -0,0 +1,282 @@
+package elastiserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/truefoundry/elasti/pkg/scaling"
+	"github.com/truefoundry/elasti/pkg/scaling/scalers"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/client-go/rest"
+)
+
+func newTestExportRequest(metricName string, value int64) *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				{Key: scalers.OTLPNamespaceAttribute, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "shop"}}},
+			}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Metrics: []*metricspb.Metric{
+					{
+						Name: metricName,
+						Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{
+							Attributes: []*commonpb.KeyValue{{Key: "region", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "eu"}}}},
+							Value:      &metricspb.NumberDataPoint_AsInt{AsInt: value},
+						}}}},
+					},
+					{
+						Name: "latency",
+						Data: &metricspb.Metric_Summary{Summary: &metricspb.Summary{DataPoints: []*metricspb.SummaryDataPoint{{}}}},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func TestOTLPMetricsHandler(t *testing.T) {
+	logger := zap.NewNop()
+	scaleHandler := scaling.NewScaleHandler(logger, &rest.Config{Host: "http://127.0.0.1:0"}, "", nil)
+	authenticator, _ := newTestAuthenticator()
+	server := NewServer(logger, scaleHandler, authenticator, time.Second)
+
+	protobufBody, err := proto.Marshal(newTestExportRequest("protobuf_sessions", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonBody, err := protojson.Marshal(newTestExportRequest("json_sessions", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	collectorBody, err := proto.Marshal(newTestExportRequest("collector_sessions", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipProtobufBody, err := proto.Marshal(newTestExportRequest("gzip_sessions", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gzipBody bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipBody)
+	if _, err := gzipWriter.Write(gzipProtobufBody); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		contentType     string
+		contentEncoding string
+		token           string
+		body            []byte
+		metricName      string
+		expectStatus    int
+		expectRejected  int64
+	}{
+		{
+			name:           "Protobuf",
+			method:         http.MethodPost,
+			token:          "shop-token",
+			contentType:    "application/x-protobuf",
+			body:           protobufBody,
+			metricName:     "protobuf_sessions",
+			expectStatus:   http.StatusOK,
+			expectRejected: 1,
+		},
+		{
+			name:           "JSON",
+			method:         http.MethodPost,
+			token:          "shop-token",
+			contentType:    "application/json; charset=utf-8",
+			body:           jsonBody,
+			metricName:     "json_sessions",
+			expectStatus:   http.StatusOK,
+			expectRejected: 1,
+		},
+		{
+			name:            "Gzip protobuf",
+			method:          http.MethodPost,
+			token:           "shop-token",
+			contentType:     "application/x-protobuf",
+			contentEncoding: "gzip",
+			body:            gzipBody.Bytes(),
+			metricName:      "gzip_sessions",
+			expectStatus:    http.StatusOK,
+			expectRejected:  1,
+		},
+		{
+			name:           "Trusted service account pushing for a namespace",
+			method:         http.MethodPost,
+			token:          "collector-token",
+			contentType:    "application/x-protobuf",
+			body:           collectorBody,
+			metricName:     "collector_sessions",
+			expectStatus:   http.StatusOK,
+			expectRejected: 1,
+		},
+		{
+			name:           "Service account pushing for another namespace",
+			method:         http.MethodPost,
+			token:          "orders-token",
+			contentType:    "application/x-protobuf",
+			body:           protobufBody,
+			expectStatus:   http.StatusOK,
+			expectRejected: 2,
+		},
+		{
+			name:         "Missing token",
+			method:       http.MethodPost,
+			contentType:  "application/x-protobuf",
+			body:         protobufBody,
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "Invalid token",
+			method:       http.MethodPost,
+			token:        "forged-token",
+			contentType:  "application/x-protobuf",
+			body:         protobufBody,
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "Unsupported content type",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			contentType:  "text/plain",
+			body:         []byte("sessions 3"),
+			expectStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:         "Invalid body",
+			method:       http.MethodPost,
+			token:        "shop-token",
+			contentType:  "application/json",
+			body:         []byte(`{"resourceMetrics":`),
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid method",
+			method:       http.MethodGet,
+			token:        "shop-token",
+			contentType:  "application/json",
+			expectStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/metrics", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.contentEncoding != "" {
+				req.Header.Set("Content-Encoding", tt.contentEncoding)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			recorder := httptest.NewRecorder()
+			server.otlpMetricsHandler(recorder, req)
+
+			if recorder.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectStatus, recorder.Code, recorder.Body.String())
+			}
+			if tt.expectStatus != http.StatusOK {
+				return
+			}
+
+			var response colmetricspb.ExportMetricsServiceResponse
+			if recorder.Header().Get("Content-Type") == "application/json" {
+				err = protojson.Unmarshal(recorder.Body.Bytes(), &response)
+			} else {
+				err = proto.Unmarshal(recorder.Body.Bytes(), &response)
+			}
+			if err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.GetPartialSuccess().GetRejectedDataPoints() != tt.expectRejected {
+				t.Errorf("expected %d rejected data points, got %v", tt.expectRejected, response.GetPartialSuccess())
+			}
+			if tt.metricName == "" {
+				return
+			}
+
+			// The pushed value is available to the otlp triggers
+			metadata, err := json.Marshal(map[string]interface{}{
+				"metricName": tt.metricName,
+				"attributes": map[string]string{"region": "eu"},
+				"threshold":  "1",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			scaler, err := scalers.NewOTLPScaler(scaleHandler.MetricStore(), "shop", metadata)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scaleFromZero, err := scaler.ShouldScaleFromZero(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !scaleFromZero {
+				t.Errorf("expected the pushed value to be above the threshold")
+			}
+		})
+	}
+}
+
+func TestOTLPMetricsServiceExport(t *testing.T) {
+	logger := zap.NewNop()
+	scaleHandler := scaling.NewScaleHandler(logger, &rest.Config{Host: "http://127.0.0.1:0"}, "", nil)
+	authenticator, _ := newTestAuthenticator()
+	service := &otlpMetricsService{server: NewServer(logger, scaleHandler, authenticator, time.Second)}
+
+	tests := []struct {
+		name       string
+		md         metadata.MD
+		expectCode codes.Code
+	}{
+		{
+			name:       "Service account",
+			md:         metadata.Pairs("authorization", "Bearer shop-token"),
+			expectCode: codes.OK,
+		},
+		{
+			name:       "Missing token",
+			md:         metadata.MD{},
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name:       "Review failed",
+			md:         metadata.Pairs("authorization", "Bearer unavailable-token"),
+			expectCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := service.Export(ctx, newTestExportRequest("grpc_sessions", 3))
+			if status.Code(err) != tt.expectCode {
+				t.Errorf("expected code %s, got %v", tt.expectCode, err)
+			}
+		})
+	}
+}
@@ -329,6 +329,26 @@ func (m *Manager) enableInformer(req *RequestWatch) error {
 	return nil
 }
 
+// GetObject returns the resource watched by the informer for the key from the cache of the informer
+func (m *Manager) GetObject(key, namespace, name string) (interface{}, error) {
+	value, ok := m.informers.Load(key)
+	if !ok {
+		return nil, fmt.Errorf("informer not found for key: %s", key)
+	}
+	informerInfo, ok := value.(info)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast WatchInfo for key: %s", key)
+	}
+	obj, exists, err := informerInfo.Informer.GetStore().GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s/%s from informer %s: %w", namespace, name, key, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("resource %s/%s not found in informer: %s", namespace, name, key)
+	}
+	return obj, nil
+}
+
 // getKeyFromRequestWatch is to get the key for the informer map using namespace and resource name from the request
 // CRDname.resourcerName.Namespace
 func (m *Manager) getKeyFromRequestWatch(req *RequestWatch) string {
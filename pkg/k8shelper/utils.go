@@ -2,9 +2,13 @@ package k8shelper
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 func UnstructuredToResource(obj interface{}, resource interface{}) error {
@@ -15,3 +19,64 @@ func UnstructuredToResource(obj interface{}, resource interface{}) error {
 	}
 	return nil
 }
+
+// DefaultReadyReplicasPath is where the scalable resources report their ready replicas
+const DefaultReadyReplicasPath = ".status.readyReplicas"
+
+// ReadyReplicas returns the ready replicas of the resource at the JSONPath, the surrounding braces are optional.
+// A missing value counts as no ready replicas, and a boolean or a condition status counts as one ready replica when it's true.
+func ReadyReplicas(obj *unstructured.Unstructured, path string) (int64, error) {
+	if path == "" {
+		path = DefaultReadyReplicasPath
+	}
+	template := path
+	if !strings.HasPrefix(template, "{") {
+		template = "{" + template + "}"
+	}
+	readyPath := jsonpath.New("readyReplicasPath").AllowMissingKeys(true)
+	if err := readyPath.Parse(template); err != nil {
+		return 0, fmt.Errorf("invalid readyReplicasPath %s: %w", path, err)
+	}
+	results, err := readyPath.FindResults(obj.UnstructuredContent())
+	if err != nil {
+		return 0, fmt.Errorf("failed to find %s: %w", path, err)
+	}
+
+	var values []reflect.Value
+	for _, result := range results {
+		values = append(values, result...)
+	}
+	switch len(values) {
+	case 0:
+		return 0, nil
+	case 1:
+	default:
+		return 0, fmt.Errorf("%s matched %d values, expected at most one", path, len(values))
+	}
+
+	switch value := values[0].Interface().(type) {
+	case int64:
+		return value, nil
+	case float64:
+		return int64(value), nil
+	case bool:
+		if value {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if ready, err := strconv.ParseBool(value); err == nil {
+			if ready {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		ready, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s has the value %q, expected a number or a boolean", path, value)
+		}
+		return ready, nil
+	default:
+		return 0, fmt.Errorf("%s has a value of type %T, expected a number or a boolean", path, value)
+	}
+}
@@ -0,0 +1,89 @@
+package k8shelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReadyReplicas(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      map[string]interface{}
+		path        string
+		expected    int64
+		expectedErr bool
+	}{
+		{
+			name:     "Default path",
+			status:   map[string]interface{}{"readyReplicas": int64(2)},
+			expected: 2,
+		},
+		{
+			name:     "Missing ready replicas",
+			status:   map[string]interface{}{"replicas": int64(1)},
+			expected: 0,
+		},
+		{
+			name:     "Custom path with braces",
+			status:   map[string]interface{}{"availableWorkerReplicas": int64(3)},
+			path:     "{.status.availableWorkerReplicas}",
+			expected: 3,
+		},
+		{
+			name: "Ready condition",
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "PredictorReady", "status": "False"},
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			}},
+			path:     `.status.conditions[?(@.type=="Ready")].status`,
+			expected: 1,
+		},
+		{
+			name: "Condition not ready",
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False"},
+			}},
+			path:     `.status.conditions[?(@.type=="Ready")].status`,
+			expected: 0,
+		},
+		{
+			name:     "Boolean",
+			status:   map[string]interface{}{"ready": true},
+			path:     ".status.ready",
+			expected: 1,
+		},
+		{
+			name:        "Several values",
+			status:      map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"status": "True"}, map[string]interface{}{"status": "True"}}},
+			path:        ".status.conditions[*].status",
+			expectedErr: true,
+		},
+		{
+			name:        "Not a number",
+			status:      map[string]interface{}{"phase": "Running"},
+			path:        ".status.phase",
+			expectedErr: true,
+		},
+		{
+			name:        "Invalid path",
+			path:        ".status[",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": tt.status}}
+			ready, err := ReadyReplicas(obj, tt.path)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ready)
+		})
+	}
+}
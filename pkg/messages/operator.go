@@ -1,7 +1,23 @@
 package messages
 
+import "time"
+
 type RequestCount struct {
+	// Count is the number of requests queued in the resolver for the service
 	Count     int    `json:"count"`
 	Svc       string `json:"svc"`
 	Namespace string `json:"namespace"`
 }
+
+// Lease is sent by a workload to keep its service awake while it's busy, e.g. during a long-running session
+type Lease struct {
+	Svc string `json:"svc"`
+	// Namespace defaults to the namespace of the service account posting the lease
+	Namespace string `json:"namespace,omitempty"`
+	// Holder identifies the lease, a lease from the same holder replaces the previous one
+	Holder string `json:"holder"`
+	// Until is when the lease expires, a time in the past releases the lease
+	Until *time.Time `json:"until,omitempty"`
+	// TTLSeconds is used when Until isn't set, the lease expires TTLSeconds from now and 0 releases it
+	TTLSeconds int `json:"ttlSeconds,omitempty"`
+}
@@ -0,0 +1,173 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ErrDependencyCycle is returned for an ElastiService which depends on itself through the dependsOn of its dependencies
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// GetElastiServiceFunc returns the ElastiService with the name in the namespace
+type GetElastiServiceFunc func(ctx context.Context, namespace, name string) (*v1alpha1.ElastiService, error)
+
+// DependencyOrder returns all the dependencies of the ElastiService, transitively, in the order they are woken up in:
+// every ElastiService comes after its own dependencies, and the ElastiService itself isn't part of them.
+func DependencyOrder(ctx context.Context, es *v1alpha1.ElastiService, get GetElastiServiceFunc) ([]*v1alpha1.ElastiService, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	states := map[types.NamespacedName]int{}
+	var order []*v1alpha1.ElastiService
+
+	var visit func(es *v1alpha1.ElastiService, path []string) error
+	visit = func(es *v1alpha1.ElastiService, path []string) error {
+		key := types.NamespacedName{Namespace: es.Namespace, Name: es.Name}
+		path = append(path, key.String())
+		switch states[key] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(path, " -> "))
+		}
+
+		states[key] = visiting
+		for _, ref := range es.Spec.DependsOn {
+			dependency := DependencyKey(es, ref)
+			dependencyES, err := get(ctx, dependency.Namespace, dependency.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get dependency %s of %s: %w", dependency, key, err)
+			}
+			if err := visit(dependencyES, path); err != nil {
+				return err
+			}
+		}
+		states[key] = visited
+		order = append(order, es)
+		return nil
+	}
+
+	if err := visit(es, nil); err != nil {
+		return nil, err
+	}
+	// The ElastiService is visited last, after all its dependencies
+	return order[:len(order)-1], nil
+}
+
+// DependencyKey returns the namespaced name of the ElastiService the reference refers to
+func DependencyKey(es *v1alpha1.ElastiService, ref v1alpha1.ElastiServiceReference) types.NamespacedName {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = es.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}
+}
+
+// DependencyKeys returns the namespaced names of the direct dependencies of the ElastiService,
+// which the ElastiServices are indexed by to find their dependents
+func DependencyKeys(es *v1alpha1.ElastiService) []string {
+	keys := make([]string, 0, len(es.Spec.DependsOn))
+	for _, ref := range es.Spec.DependsOn {
+		keys = append(keys, DependencyKey(es, ref).String())
+	}
+	return keys
+}
+
+// errElastiServicesNotWatched is returned when the ElastiServices are looked up before the evaluation queue is started
+var errElastiServicesNotWatched = errors.New("the ElastiServices aren't watched yet")
+
+// syncedEvaluations returns the evaluation queue once its informer has synced, its cache is the only source
+// of the ElastiServices looked up by their dependencies, so they are never read from the API server
+func (h *ScaleHandler) syncedEvaluations(ctx context.Context) (*evaluationQueue, error) {
+	queue := h.evaluations.Load()
+	if queue == nil {
+		return nil, errElastiServicesNotWatched
+	}
+	if !queue.informer.Informer().HasSynced() && !cache.WaitForCacheSync(ctx.Done(), queue.informer.Informer().HasSynced) {
+		return nil, fmt.Errorf("failed to sync the ElastiService informer: %w", ctx.Err())
+	}
+	return queue, nil
+}
+
+// getElastiService reads the ElastiService from the informer of the evaluation queue
+func (h *ScaleHandler) getElastiService(ctx context.Context, namespace, name string) (*v1alpha1.ElastiService, error) {
+	queue, err := h.syncedEvaluations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	es, err := queue.get(namespace + "/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ElastiService %s/%s: %w", namespace, name, err)
+	}
+	return es, nil
+}
+
+// dependents returns the ElastiServices which directly depend on the ElastiService.
+// They are read from the informer of the evaluation queue, indexed by their dependencies.
+func (h *ScaleHandler) dependents(ctx context.Context, es *v1alpha1.ElastiService) ([]*v1alpha1.ElastiService, error) {
+	queue, err := h.syncedEvaluations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queue.dependents(types.NamespacedName{Namespace: es.Namespace, Name: es.Name})
+}
+
+// WakeDependencies wakes up all the dependencies of the ElastiService, in the order of DependencyOrder.
+// A dependency which fails to wake up doesn't keep the others asleep.
+func (h *ScaleHandler) WakeDependencies(ctx context.Context, es *v1alpha1.ElastiService) error {
+	if len(es.Spec.DependsOn) == 0 {
+		return nil
+	}
+	dependencies, err := DependencyOrder(ctx, es, h.getElastiService)
+	if err != nil {
+		h.createEvent(es.Namespace, es.Name, "Warning", "WakeDependenciesFailed", fmt.Sprintf("Failed to wake up the dependencies: %v", err))
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	var errs []error
+	for _, dependency := range dependencies {
+		h.logger.Info("Waking up dependency",
+			zap.String("es", es.Namespace+"/"+es.Name),
+			zap.String("dependency", dependency.Namespace+"/"+dependency.Name))
+		if err := h.wakeElastiService(ctx, dependency); err != nil {
+			errs = append(errs, fmt.Errorf("failed to wake up dependency %s/%s: %w", dependency.Namespace, dependency.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// awakeDependent returns a dependent of the ElastiService which is scaled above its idle replicas, if any.
+// No target is read for an ElastiService nothing depends on.
+func (h *ScaleHandler) awakeDependent(ctx context.Context, es *v1alpha1.ElastiService) (*v1alpha1.ElastiService, error) {
+	dependents, err := h.dependents(ctx, es)
+	if err != nil || len(dependents) == 0 {
+		return nil, err
+	}
+	for _, dependent := range dependents {
+		targets := dependent.Spec.ScaleTargets()
+		if len(targets) == 0 {
+			continue
+		}
+		replicas, err := h.TargetReplicas(ctx, dependent.Namespace, targets[0].ScaleTargetRef)
+		if err != nil {
+			// A dependent whose target can't be read doesn't keep its dependencies awake forever
+			h.logger.Warn("Failed to get replicas of dependent",
+				zap.String("es", es.Namespace+"/"+es.Name),
+				zap.String("dependent", dependent.Namespace+"/"+dependent.Name),
+				zap.Error(err))
+			continue
+		}
+		if replicas > dependent.Spec.IdleReplicas {
+			return dependent, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,200 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/truefoundry/elasti/pkg/values"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newDependentElastiService(name, target string, dependsOn ...string) *v1alpha1.ElastiService {
+	es := &v1alpha1.ElastiService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		Spec: v1alpha1.ElastiServiceSpec{
+			Service:        name,
+			ScaleTargetRef: &v1alpha1.ScaleTargetRef{APIVersion: "serving.example.com/v1", Kind: "modelservers", Name: target},
+		},
+	}
+	for _, dependency := range dependsOn {
+		es.Spec.DependsOn = append(es.Spec.DependsOn, v1alpha1.ElastiServiceReference{Name: dependency})
+	}
+	return es
+}
+
+func getFrom(services ...*v1alpha1.ElastiService) GetElastiServiceFunc {
+	return func(_ context.Context, namespace, name string) (*v1alpha1.ElastiService, error) {
+		for _, es := range services {
+			if es.Namespace == namespace && es.Name == name {
+				return es, nil
+			}
+		}
+		return nil, fmt.Errorf("ElastiService %s/%s not found", namespace, name)
+	}
+}
+
+func TestDependencyOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		services    []*v1alpha1.ElastiService
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name:     "No dependencies",
+			services: []*v1alpha1.ElastiService{newDependentElastiService("frontend", "frontend")},
+		},
+		{
+			name: "Chain",
+			services: []*v1alpha1.ElastiService{
+				newDependentElastiService("frontend", "frontend", "api"),
+				newDependentElastiService("api", "api", "cache"),
+				newDependentElastiService("cache", "cache"),
+			},
+			expected: []string{"cache", "api"},
+		},
+		{
+			name: "Shared dependency",
+			services: []*v1alpha1.ElastiService{
+				newDependentElastiService("frontend", "frontend", "api", "auth"),
+				newDependentElastiService("api", "api", "cache"),
+				newDependentElastiService("auth", "auth", "cache"),
+				newDependentElastiService("cache", "cache"),
+			},
+			expected: []string{"cache", "api", "auth"},
+		},
+		{
+			name: "Cycle",
+			services: []*v1alpha1.ElastiService{
+				newDependentElastiService("frontend", "frontend", "api"),
+				newDependentElastiService("api", "api", "auth"),
+				newDependentElastiService("auth", "auth", "api"),
+			},
+			expectedErr: ErrDependencyCycle,
+		},
+		{
+			name: "Depends on itself",
+			services: []*v1alpha1.ElastiService{
+				newDependentElastiService("frontend", "frontend", "frontend"),
+			},
+			expectedErr: ErrDependencyCycle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := DependencyOrder(context.Background(), tt.services[0], getFrom(tt.services...))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, es := range order {
+				names = append(names, es.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+
+	// A missing dependency can't be woken up
+	_, err := DependencyOrder(context.Background(), newDependentElastiService("frontend", "frontend", "api"), getFrom())
+	assert.ErrorContains(t, err, "shop/api")
+}
+
+func TestAwakeDependent(t *testing.T) {
+	var objects []runtime.Object
+	for _, es := range []*v1alpha1.ElastiService{
+		newDependentElastiService("frontend", "frontend", "api"),
+		newDependentElastiService("admin", "admin", "api", "auth"),
+		newDependentElastiService("api", "api", "cache"),
+		newDependentElastiService("cache", "cache"),
+	} {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(es)
+		require.NoError(t, err)
+		obj := &unstructured.Unstructured{Object: content}
+		obj.SetAPIVersion(values.ElastiServiceGVR.GroupVersion().String())
+		obj.SetKind("ElastiService")
+		objects = append(objects, obj)
+	}
+	dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{values.ElastiServiceGVR: "ElastiServiceList"}, objects...)
+
+	replicas := map[string]int32{"frontend": 0, "admin": 0, "api": 2, "cache": 1}
+	h := newFakeScaleHandler(replicas, 0, new(int))
+	h.kDynamicClient = dynamicClient
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Before the evaluation queue runs, the dependents aren't read from the API server
+	_, err := h.dependents(ctx, newDependentElastiService("api", "api"))
+	assert.ErrorIs(t, err, errElastiServicesNotWatched)
+	assert.Empty(t, dynamicClient.Actions())
+
+	runEvaluationQueue(ctx, t, h)
+	listed := countActions(dynamicClient, "list")
+
+	// Once it has synced, they are read from its cache by their dependencies
+	dependents, err := h.dependents(ctx, newDependentElastiService("api", "api"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"frontend", "admin"}, []string{dependents[0].Name, dependents[1].Name})
+	dependents, err = h.dependents(ctx, newDependentElastiService("frontend", "frontend"))
+	require.NoError(t, err)
+	assert.Empty(t, dependents)
+
+	// The cache is kept awake by the api, which isn't kept awake by its sleeping dependents
+	dependent, err := h.awakeDependent(ctx, newDependentElastiService("cache", "cache"))
+	require.NoError(t, err)
+	require.NotNil(t, dependent)
+	assert.Equal(t, "api", dependent.Name)
+
+	dependent, err = h.awakeDependent(ctx, newDependentElastiService("api", "api"))
+	require.NoError(t, err)
+	assert.Nil(t, dependent)
+
+	// Once a dependent is woken up, its dependency is kept awake as well
+	replicas["admin"] = 1
+	dependent, err = h.awakeDependent(ctx, newDependentElastiService("api", "api"))
+	require.NoError(t, err)
+	require.NotNil(t, dependent)
+	assert.Equal(t, "admin", dependent.Name)
+
+	// The dependencies are read from the cache as well
+	dependencies, err := DependencyOrder(ctx, newDependentElastiService("admin", "admin", "api", "auth"), h.getElastiService)
+	assert.ErrorContains(t, err, "shop/auth")
+	assert.Nil(t, dependencies)
+	dependencies, err = DependencyOrder(ctx, newDependentElastiService("frontend", "frontend", "api"), h.getElastiService)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cache", "api"}, []string{dependencies[0].Name, dependencies[1].Name})
+	assert.Equal(t, listed, countActions(dynamicClient, "list"))
+	assert.Zero(t, countActions(dynamicClient, "get"))
+}
+
+// runEvaluationQueue runs an evaluation queue without workers for the ScaleHandler, so its informer caches the ElastiServices
+func runEvaluationQueue(ctx context.Context, t *testing.T, h *ScaleHandler) {
+	queue, err := newEvaluationQueue(zap.NewNop(), h.kDynamicClient, "", time.Hour, func(context.Context, *v1alpha1.ElastiService) error { return nil })
+	require.NoError(t, err)
+	h.evaluations.Store(queue)
+	go queue.run(ctx, 0)
+	require.Eventually(t, queue.informer.Informer().HasSynced, 5*time.Second, 10*time.Millisecond)
+}
+
+func countActions(client *fake.FakeDynamicClient, verb string) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == verb {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,231 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/truefoundry/elasti/pkg/values"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// pollingJitterFactor is the fraction of the polling interval added at random to every wait between evaluations
+	pollingJitterFactor = 0.1
+	// evaluationBaseBackoff and evaluationMaxBackoff bound the per-service backoff of failed evaluations
+	evaluationBaseBackoff = time.Second
+	evaluationMaxBackoff  = 5 * time.Minute
+
+	// dependsOnIndex indexes the ElastiServices by the namespaced names of the ElastiServices they depend on
+	dependsOnIndex = "dependsOn"
+)
+
+// evaluationQueue schedules the evaluations of the ElastiServices on a rate limited workqueue.
+// The ElastiServices are read from an informer, and each one is re-enqueued for its next due evaluation
+// once it has been evaluated, so a bounded pool of workers only ever handles the services which are due
+// and a slow trigger only delays the evaluations of its own service.
+type evaluationQueue struct {
+	logger          *zap.Logger
+	informer        informers.GenericInformer
+	queue           workqueue.TypedRateLimitingInterface[string]
+	rateLimiter     workqueue.TypedRateLimiter[string]
+	defaultInterval time.Duration
+	evaluate        func(ctx context.Context, es *v1alpha1.ElastiService) error
+}
+
+func newEvaluationQueue(logger *zap.Logger, client dynamic.Interface, namespace string, defaultInterval time.Duration,
+	evaluate func(ctx context.Context, es *v1alpha1.ElastiService) error) (*evaluationQueue, error) {
+	rateLimiter := workqueue.NewTypedItemExponentialFailureRateLimiter[string](evaluationBaseBackoff, evaluationMaxBackoff)
+	q := &evaluationQueue{
+		logger: logger,
+		informer: dynamicinformer.NewFilteredDynamicInformer(client, values.ElastiServiceGVR, namespace, 0,
+			cache.Indexers{dependsOnIndex: dependsOnIndexFunc}, nil),
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[string]{
+			Name: "elastiservice_evaluations",
+		}),
+		rateLimiter:     rateLimiter,
+		defaultInterval: defaultInterval,
+		evaluate:        evaluate,
+	}
+
+	_, err := q.informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			// A random offset spreads the first evaluations of the services added together,
+			// e.g. when the operator starts, across their interval
+			q.enqueueAfterOffset(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// The next evaluation was scheduled with the previous interval, so it is brought forward when the interval changes
+			if pollingIntervalOf(oldObj) != pollingIntervalOf(newObj) {
+				q.enqueueAfterOffset(newObj)
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add ElastiService event handler: %w", err)
+	}
+	return q, nil
+}
+
+// run starts the informer and the workers, and blocks until the context is cancelled
+func (q *evaluationQueue) run(ctx context.Context, workers int) {
+	defer q.queue.ShutDown()
+
+	go q.informer.Informer().Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), q.informer.Informer().HasSynced) {
+		q.logger.Error("failed to sync the ElastiService informer")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, q.runWorker, time.Second)
+	}
+	<-ctx.Done()
+}
+
+func (q *evaluationQueue) runWorker(ctx context.Context) {
+	for q.processNextItem(ctx) {
+	}
+}
+
+func (q *evaluationQueue) processNextItem(ctx context.Context) bool {
+	key, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(key)
+
+	es, err := q.get(key)
+	if errors.IsNotFound(err) {
+		// The ElastiService was deleted, so it isn't scheduled again
+		q.queue.Forget(key)
+		return true
+	}
+	if err != nil {
+		q.logger.Error("failed to get ElastiService", zap.String("key", key), zap.Error(err))
+		q.queue.AddRateLimited(key)
+		return true
+	}
+
+	next := wait.Jitter(resolvePollingInterval(es, q.defaultInterval), pollingJitterFactor)
+	if err := q.evaluate(ctx, es); err != nil {
+		q.logger.Error("failed to evaluate ElastiService", zap.String("key", key), zap.Error(err))
+		// Failed evaluations are retried with a per-service backoff, but never later than the next due evaluation
+		q.queue.AddAfter(key, min(q.rateLimiter.When(key), next))
+		return true
+	}
+	q.queue.Forget(key)
+	q.queue.AddAfter(key, next)
+	return true
+}
+
+func (q *evaluationQueue) get(key string) (*v1alpha1.ElastiService, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ElastiService key %s: %w", key, err)
+	}
+	obj, err := q.informer.Lister().ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return toElastiService(obj)
+}
+
+// dependents returns the ElastiServices which directly depend on the ElastiService with the key, from the informer cache
+func (q *evaluationQueue) dependents(key types.NamespacedName) ([]*v1alpha1.ElastiService, error) {
+	objs, err := q.informer.Informer().GetIndexer().ByIndex(dependsOnIndex, key.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependents of %s: %w", key, err)
+	}
+	dependents := make([]*v1alpha1.ElastiService, 0, len(objs))
+	for _, obj := range objs {
+		dependent, err := toElastiService(obj.(runtime.Object))
+		if err != nil {
+			return nil, err
+		}
+		dependents = append(dependents, dependent)
+	}
+	return dependents, nil
+}
+
+// dependsOnIndexFunc returns the namespaced names of the dependencies of the unstructured ElastiService
+func dependsOnIndexFunc(obj interface{}) ([]string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil
+	}
+	if _, found, _ := unstructured.NestedSlice(u.Object, "spec", "dependsOn"); !found {
+		return nil, nil
+	}
+	es, err := toElastiService(u)
+	if err != nil {
+		return nil, err
+	}
+	return DependencyKeys(es), nil
+}
+
+func (q *evaluationQueue) enqueueAfterOffset(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		q.logger.Error("failed to get ElastiService key", zap.Error(err))
+		return
+	}
+	q.queue.AddAfter(key, pollingOffset(q.pollingInterval(obj)))
+}
+
+func (q *evaluationQueue) pollingInterval(obj interface{}) time.Duration {
+	if interval := pollingIntervalOf(obj); interval > 0 {
+		return time.Second * time.Duration(interval)
+	}
+	return q.defaultInterval
+}
+
+// pollingIntervalOf reads spec.pollingInterval of the unstructured ElastiService
+func pollingIntervalOf(obj interface{}) int32 {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return 0
+	}
+	interval, _, _ := unstructured.NestedInt64(u.Object, "spec", "pollingInterval")
+	return int32(interval)
+}
+
+func toElastiService(obj runtime.Object) (*v1alpha1.ElastiService, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	es := &v1alpha1.ElastiService{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, es); err != nil {
+		return nil, fmt.Errorf("failed to convert unstructured to ElastiService: %w", err)
+	}
+	return es, nil
+}
+
+// pollingOffset returns a random delay within the interval
+func pollingOffset(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(interval)))
+}
+
+func resolvePollingInterval(es *v1alpha1.ElastiService, defaultInterval time.Duration) time.Duration {
+	pollingInterval := time.Second * time.Duration(es.Spec.PollingInterval)
+	if pollingInterval == 0 {
+		pollingInterval = defaultInterval
+	}
+	return pollingInterval
+}
@@ -0,0 +1,103 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/truefoundry/elasti/pkg/values"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newElastiService(name string, pollingInterval int64) *unstructured.Unstructured {
+	es := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": values.ElastiServiceGVR.GroupVersion().String(),
+		"kind":       "ElastiService",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "shop",
+		},
+		"spec": map[string]interface{}{
+			"service": name,
+		},
+	}}
+	if pollingInterval > 0 {
+		es.Object["spec"].(map[string]interface{})["pollingInterval"] = pollingInterval
+	}
+	return es
+}
+
+func TestEvaluationQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{values.ElastiServiceGVR: "ElastiServiceList"},
+		newElastiService("checkout", 0),
+		newElastiService("orders", 0),
+		newElastiService("staging", 3600),
+	)
+
+	var mu sync.Mutex
+	evaluations := map[string]int{}
+	evaluate := func(_ context.Context, es *v1alpha1.ElastiService) error {
+		mu.Lock()
+		defer mu.Unlock()
+		evaluations[es.Name]++
+		if es.Name == "orders" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	count := func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return evaluations[name]
+	}
+
+	queue, err := newEvaluationQueue(zap.NewNop(), client, "", 50*time.Millisecond, evaluate)
+	require.NoError(t, err)
+	go queue.run(ctx, 2)
+
+	// Services are evaluated again at their polling interval, and failed evaluations are retried
+	assert.Eventually(t, func() bool { return count("checkout") >= 3 && count("orders") >= 2 }, 5*time.Second, 10*time.Millisecond)
+	// A service polled every hour isn't due again
+	assert.LessOrEqual(t, count("staging"), 1)
+
+	// Deleted services are no longer evaluated
+	require.NoError(t, client.Resource(values.ElastiServiceGVR).Namespace("shop").Delete(ctx, "checkout", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		_, err := queue.informer.Lister().ByNamespace("shop").Get("checkout")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	deleted := count("checkout")
+	time.Sleep(200 * time.Millisecond)
+	assert.LessOrEqual(t, count("checkout"), deleted+1)
+}
+
+func TestPollingOffset(t *testing.T) {
+	assert.Zero(t, pollingOffset(0))
+	for i := 0; i < 100; i++ {
+		offset := pollingOffset(time.Minute)
+		assert.GreaterOrEqual(t, offset, time.Duration(0))
+		assert.Less(t, offset, time.Minute)
+	}
+}
+
+func TestResolvePollingInterval(t *testing.T) {
+	es := &v1alpha1.ElastiService{}
+	assert.Equal(t, 30*time.Second, resolvePollingInterval(es, 30*time.Second))
+
+	es.Spec.PollingInterval = 10
+	assert.Equal(t, 10*time.Second, resolvePollingInterval(es, 30*time.Second))
+}
@@ -2,11 +2,14 @@ package scaling
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"truefoundry/elasti/operator/api/v1alpha1"
 
@@ -14,12 +17,17 @@ import (
 	"github.com/truefoundry/elasti/pkg/values"
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/scale"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -38,10 +46,23 @@ const (
 
 type ScaleHandler struct {
 	kClient        *kubernetes.Clientset
-	kDynamicClient *dynamic.DynamicClient
+	kDynamicClient dynamic.Interface
 	EventRecorder  record.EventRecorder
+	// scaleClient scales the targets through their scale subresource, whose group resource is resolved with restMapper
+	scaleClient scale.ScalesGetter
+	restMapper  meta.RESTMapper
 
 	scaleLocks sync.Map
+	// scalerCache is shared by the scalers of all the ElastiServices
+	scalerCache *scalers.Cache
+	// metricStore holds the metrics pushed to the operator over OTLP, for the otlp triggers
+	metricStore *scalers.MetricStore
+	// leaseStore holds the leases posted by the workloads, for the heartbeat triggers
+	leaseStore *scalers.LeaseStore
+	// pollingInterval is used for the ElastiServices which don't set their own
+	pollingInterval time.Duration
+	// evaluations is the evaluation queue, whose informer caches the ElastiServices once StartScaleDownWatcher has run
+	evaluations atomic.Pointer[evaluationQueue]
 
 	logger         *zap.Logger
 	watchNamespace string
@@ -65,15 +86,56 @@ func NewScaleHandler(logger *zap.Logger, config *rest.Config, watchNamespace str
 		logger.Fatal("Error connecting with kubernetes", zap.Error(err))
 	}
 
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		logger.Fatal("Error connecting with kubernetes", zap.Error(err))
+	}
+	cachedDiscoveryClient := memory.NewMemCacheClient(discoveryClient)
+	restMapper := restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscoveryClient)
+	scaleClient, err := scale.NewForConfig(config, restMapper, dynamic.LegacyAPIPathResolverFunc, scale.NewDiscoveryScaleKindResolver(cachedDiscoveryClient))
+	if err != nil {
+		logger.Fatal("Error connecting with kubernetes", zap.Error(err))
+	}
+
 	return &ScaleHandler{
 		logger:         logger.Named("ScaleHandler"),
 		kClient:        kClient,
 		kDynamicClient: kDynamicClient,
+		scaleClient:    scaleClient,
+		restMapper:     restMapper,
 		watchNamespace: watchNamespace,
 		EventRecorder:  eventRecorder,
+		metricStore:    scalers.NewMetricStore(),
+		leaseStore:     scalers.NewLeaseStore(maxLeaseDuration(logger)),
+	}
+}
+
+// maxLeaseDuration returns how long a lease can keep a service awake, set with MAX_LEASE_DURATION
+func maxLeaseDuration(logger *zap.Logger) time.Duration {
+	envDuration := os.Getenv("MAX_LEASE_DURATION")
+	if envDuration == "" {
+		return scalers.DefaultMaxLeaseDuration
+	}
+	duration, err := time.ParseDuration(envDuration)
+	if err != nil || duration <= 0 {
+		logger.Warn("Invalid MAX_LEASE_DURATION value, using default 1h", zap.String("value", envDuration))
+		return scalers.DefaultMaxLeaseDuration
 	}
+	return duration
 }
 
+// MetricStore returns the store the OTLP receiver records the pushed metrics in
+func (h *ScaleHandler) MetricStore() *scalers.MetricStore {
+	return h.metricStore
+}
+
+// LeaseStore returns the store the lease endpoint records the leases in
+func (h *ScaleHandler) LeaseStore() *scalers.LeaseStore {
+	return h.leaseStore
+}
+
+// StartScaleDownWatcher evaluates every ElastiService at its pollingInterval, using SCALE_WORKERS workers.
+// POLLING_VARIABLE sets the polling interval of the ElastiServices which don't set their own.
 func (h *ScaleHandler) StartScaleDownWatcher(ctx context.Context) {
 	pollingInterval := 30 * time.Second
 	if envInterval := os.Getenv("POLLING_VARIABLE"); envInterval != "" {
@@ -84,8 +146,29 @@ func (h *ScaleHandler) StartScaleDownWatcher(ctx context.Context) {
 			pollingInterval = duration
 		}
 	}
-	ticker := time.NewTicker(pollingInterval)
+	workers := 10
+	if envWorkers := os.Getenv("SCALE_WORKERS"); envWorkers != "" {
+		n, err := strconv.Atoi(envWorkers)
+		if err != nil || n < 1 {
+			h.logger.Warn("Invalid SCALE_WORKERS value, using default 10", zap.String("value", envWorkers))
+		} else {
+			workers = n
+		}
+	}
+	h.pollingInterval = pollingInterval
+	// Results are kept for half the polling interval of the services using them, so the services evaluated
+	// within the same interval share them while every evaluation still sees fresh results
+	h.scalerCache = scalers.NewCache(pollingInterval / 2)
 
+	queue, err := newEvaluationQueue(h.logger, h.kDynamicClient, h.watchNamespace, pollingInterval, h.evaluateElastiService)
+	if err != nil {
+		h.logger.Error("failed to create the ElastiService evaluation queue", zap.Error(err))
+		return
+	}
+	h.evaluations.Store(queue)
+	go queue.run(ctx, workers)
+
+	ticker := time.NewTicker(pollingInterval)
 	go func() {
 		for {
 			select {
@@ -93,54 +176,39 @@ func (h *ScaleHandler) StartScaleDownWatcher(ctx context.Context) {
 				ticker.Stop()
 				return
 			case <-ticker.C:
-				if err := h.checkAndScale(ctx); err != nil {
-					h.logger.Error("failed to run the scale down check", zap.Error(err))
-				}
+				h.scalerCache.EvictExpired()
+				h.metricStore.EvictStale()
+				h.leaseStore.EvictExpired()
 			}
 		}
 	}()
 }
 
-func (h *ScaleHandler) checkAndScale(ctx context.Context) error {
-	elastiServiceList, err := h.kDynamicClient.Resource(values.ElastiServiceGVR).Namespace(h.watchNamespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to list ElastiServices: %w", err)
+// evaluateElastiService evaluates the triggers of the ElastiService and scales its target accordingly
+func (h *ScaleHandler) evaluateElastiService(ctx context.Context, es *v1alpha1.ElastiService) error {
+	// Invalid triggers are reported in the TriggersValid condition by the reconciler, they aren't evaluated until they are fixed
+	if err := ValidateTriggers(&es.Spec); err != nil {
+		h.logger.Debug("Skipping evaluation of invalid triggers", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service), zap.Error(err))
+		return nil
 	}
+	cooldownPeriod := resolveCooldownPeriod(es)
 
-	for _, item := range elastiServiceList.Items {
-		es := &v1alpha1.ElastiService{}
-		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, es); err != nil {
-			h.logger.Error("failed to convert unstructured to ElastiService", zap.Error(err))
-			continue
-		}
-		cooldownPeriod := resolveCooldownPeriod(es)
-
-		scaleDirection, err := h.calculateScaleDirection(ctx, cooldownPeriod, es)
-		if err != nil {
-			h.logger.Error("failed to calculate scale direction", zap.String("service", es.Spec.Service), zap.String("namespace", es.Namespace), zap.Error(err))
-			continue
-		}
+	scaleDirection, err := h.calculateScaleDirection(ctx, cooldownPeriod, es)
+	if err != nil {
+		return fmt.Errorf("failed to calculate scale direction: %w", err)
+	}
 
-		if scaleDirection == NoScale {
-			continue
+	scaleDirection = h.applyIdleEvaluations(ctx, es, scaleDirection)
+	switch scaleDirection {
+	case ScaleDown:
+		if err := h.handleScaleToZero(ctx, cooldownPeriod, es); err != nil {
+			return fmt.Errorf("failed to scale target to zero: %w", err)
 		}
-
-		switch scaleDirection {
-		case ScaleDown:
-			err := h.handleScaleToZero(ctx, cooldownPeriod, es)
-			if err != nil {
-				h.logger.Error("failed to scale target to zero", zap.String("service", es.Spec.Service), zap.String("namespace", es.Namespace), zap.Error(err))
-				continue
-			}
-		case ScaleUp:
-			err := h.handleScaleFromZero(ctx, es)
-			if err != nil {
-				h.logger.Error("failed to scale target from zero", zap.String("service", es.Spec.Service), zap.String("namespace", es.Namespace), zap.Error(err))
-				continue
-			}
+	case ScaleUp:
+		if err := h.handleScaleFromZero(ctx, es); err != nil {
+			return fmt.Errorf("failed to scale target from zero: %w", err)
 		}
 	}
-
 	return nil
 }
 
@@ -159,44 +227,133 @@ func (h *ScaleHandler) calculateScaleDirection(ctx context.Context, cooldownPeri
 		return NoScale, nil
 	}
 
-	for _, trigger := range es.Spec.Triggers {
-		scaler, err := h.createScalerForTrigger(&trigger, cooldownPeriod)
-		if err != nil {
-			h.logger.Warn("failed to create scaler", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service), zap.Error(err))
-			return "", fmt.Errorf("failed to create scaler: %w", err)
-		}
-		defer scaler.Close(ctx)
+	names := triggerNames(es.Spec.Triggers)
+	results := make([]v1alpha1.TriggerResult, 0, len(es.Spec.Triggers))
+	idle := make([]bool, 0, len(es.Spec.Triggers))
+	anyActive := false
+	for i := range es.Spec.Triggers {
+		result := h.evaluateTrigger(ctx, &es.Spec.Triggers[i], names[i], cooldownPeriod, es)
+		idle = append(idle, result.Result == v1alpha1.TriggerResultIdle)
+		anyActive = anyActive || result.Result == v1alpha1.TriggerResultActive
+		results = append(results, result)
+	}
+
+	evaluation := &v1alpha1.TriggerEvaluation{
+		Policy:   es.Spec.TriggerPolicy,
+		Triggers: results,
+	}
+	scaleToZero, policyErr := evaluateTriggerPolicy(es.Spec.TriggerPolicy, names, idle)
+	direction := NoScale
+	switch {
+	case policyErr != nil:
+		evaluation.Message = policyErr.Error()
+	case scaleToZero:
+		direction = ScaleDown
+	case anyActive:
+		direction = ScaleUp
+	default:
+		// Only unhealthy triggers are preventing the scale to zero, so there is nothing to act on
+		evaluation.Message = "triggers are unhealthy"
+	}
+	evaluation.ScaleToZero = scaleToZero
+	evaluation.Direction = string(direction)
 
-		// TODO: Cache the health of the scaler if the server address has already been checked
-		healthy, err := scaler.IsHealthy(ctx)
-		if err != nil {
-			h.logger.Warn(
-				"failed to check scaler health",
-				zap.String("namespace", es.Namespace),
-				zap.String("service", es.Spec.Service),
-				zap.String("scaler", trigger.Type),
-				zap.Duration("cooldownPeriod", cooldownPeriod),
-				zap.Error(err),
-			)
-			return NoScale, nil
-		}
-		if !healthy {
-			h.logger.Warn("scaler is not healthy, skipping scale to zero", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service))
-			return "", fmt.Errorf("scaler: %s, cooldownPeriod: %s, is not healthy", trigger.Type, cooldownPeriod)
-		}
+	if err := h.updateTriggerEvaluation(ctx, es, evaluation); err != nil {
+		h.logger.Warn("failed to update trigger evaluation", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service), zap.Error(err))
+	}
+	if policyErr != nil {
+		return "", policyErr
+	}
+	return direction, nil
+}
 
-		scaleToZero, err := scaler.ShouldScaleToZero(ctx)
-		if err != nil {
-			h.logger.Warn("failed to check scaler", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service), zap.Error(err))
-			return "", fmt.Errorf("failed to check scaler: %w", err)
+// applyIdleEvaluations counts the consecutive idle evaluations in the status of the ElastiService,
+// and holds the scale down until the triggers have been idle for minIdleEvaluations.
+// Evaluations which are neither idle nor active, e.g. because of unhealthy triggers, don't change the count.
+// The count stops at minIdleEvaluations, so the status isn't patched on every evaluation of an idle service.
+func (h *ScaleHandler) applyIdleEvaluations(ctx context.Context, es *v1alpha1.ElastiService, direction ScaleDirection) ScaleDirection {
+	minIdleEvaluations := max(es.Spec.MinIdleEvaluations, 1)
+	count := es.Status.ConsecutiveIdleEvaluations
+	switch {
+	case es.Status.Mode == values.ProxyMode:
+		// The service is already at zero, so the count starts over for when it is woken up
+		count = 0
+	case direction == ScaleDown:
+		count = min(count+1, minIdleEvaluations)
+	case direction == ScaleUp:
+		count = 0
+	}
+
+	if count != es.Status.ConsecutiveIdleEvaluations {
+		if err := h.UpdateConsecutiveIdleEvaluations(ctx, es.Name, es.Namespace, count); err != nil {
+			h.logger.Warn("failed to update consecutive idle evaluations", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service), zap.Error(err))
 		}
+	}
 
-		if !scaleToZero {
-			return ScaleUp, nil
-		}
+	if direction == ScaleDown && es.Status.Mode != values.ProxyMode && count < minIdleEvaluations {
+		h.logger.Debug("Holding scale down until the triggers are idle for enough evaluations",
+			zap.String("namespace", es.Namespace),
+			zap.String("service", es.Spec.Service),
+			zap.Int32("consecutiveIdleEvaluations", count),
+			zap.Int32("minIdleEvaluations", minIdleEvaluations))
+		return NoScale
 	}
+	return direction
+}
 
-	return ScaleDown, nil
+// evaluateTrigger checks whether the trigger is idle, a trigger which can't be checked is reported as unhealthy
+func (h *ScaleHandler) evaluateTrigger(ctx context.Context, trigger *v1alpha1.ScaleTrigger, name string, cooldownPeriod time.Duration, es *v1alpha1.ElastiService) v1alpha1.TriggerResult {
+	result := v1alpha1.TriggerResult{Name: name, Result: v1alpha1.TriggerResultUnhealthy}
+
+	scaler, err := h.createScalerForTrigger(ctx, trigger, cooldownPeriod, es)
+	if err != nil {
+		h.logger.Warn("failed to create scaler", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service), zap.Error(err))
+		result.Message = err.Error()
+		return result
+	}
+	defer scaler.Close(ctx)
+
+	healthy, err := scaler.IsHealthy(ctx)
+	if err != nil {
+		h.logger.Warn(
+			"failed to check scaler health",
+			zap.String("namespace", es.Namespace),
+			zap.String("service", es.Spec.Service),
+			zap.String("scaler", trigger.Type),
+			zap.Duration("cooldownPeriod", cooldownPeriod),
+			zap.Error(err),
+		)
+		result.Message = err.Error()
+		return result
+	}
+	if !healthy {
+		h.logger.Warn("scaler is not healthy", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service), zap.String("scaler", trigger.Type))
+		result.Message = fmt.Sprintf("scaler is not healthy, cooldownPeriod: %s", cooldownPeriod)
+		return result
+	}
+
+	// A service at zero is woken up by its triggers using their activation condition,
+	// which may differ from the condition used to scale it down
+	var active bool
+	if es.Status.Mode == values.ProxyMode {
+		active, err = scaler.ShouldScaleFromZero(ctx)
+	} else {
+		var scaleToZero bool
+		scaleToZero, err = scaler.ShouldScaleToZero(ctx)
+		active = !scaleToZero
+	}
+	if err != nil {
+		h.logger.Warn("failed to check scaler", zap.String("namespace", es.Namespace), zap.String("service", es.Spec.Service), zap.Error(err))
+		result.Message = err.Error()
+		return result
+	}
+
+	if active {
+		result.Result = v1alpha1.TriggerResultActive
+	} else {
+		result.Result = v1alpha1.TriggerResultIdle
+	}
+	return result
 }
 
 func (h *ScaleHandler) handleScaleToZero(ctx context.Context, cooldownPeriod time.Duration, es *v1alpha1.ElastiService) error {
@@ -216,18 +373,40 @@ func (h *ScaleHandler) handleScaleToZero(ctx context.Context, cooldownPeriod tim
 		}
 	}
 
+	// A dependency is kept awake while any of its dependents is awake
+	dependent, err := h.awakeDependent(ctx, es)
+	if err != nil {
+		return fmt.Errorf("failed to check dependents of service %s: %w", serviceNamespacedName.String(), err)
+	}
+	if dependent != nil {
+		h.logger.Debug("Skipping scale down as a dependent is awake",
+			zap.String("service", serviceNamespacedName.String()),
+			zap.String("dependent", dependent.Namespace+"/"+dependent.Name))
+		return nil
+	}
+
+	// The replicas are recorded before scaling down, so the target can be woken up with them again.
+	// This happens before pausing KEDA, which scales the target down to the paused replicas on its own.
+	if err := h.recordReplicasBeforeIdle(ctx, es); err != nil {
+		return fmt.Errorf("failed to record replicas before scaling down: %w", err)
+	}
+
 	// Pause the KEDA ScaledObject
 	if es.Spec.Autoscaler != nil && strings.ToLower(es.Spec.Autoscaler.Type) == "keda" {
-		err := h.UpdateKedaScaledObjectPausedState(ctx, es.Spec.Autoscaler.Name, es.Namespace, true)
+		err := h.UpdateKedaScaledObjectPausedState(ctx, es.Spec.Autoscaler.Name, es.Namespace, true, es.Spec.IdleReplicas)
 		if err != nil {
 			return fmt.Errorf("failed to update Keda ScaledObject for service %s: %w", serviceNamespacedName.String(), err)
 		}
 	}
 
-	if err := h.ScaleTargetToZero(ctx, serviceNamespacedName, es.Spec.ScaleTargetRef.Kind, es.Spec.ScaleTargetRef.Name, es.Name); err != nil {
-		return fmt.Errorf("failed to scale target to zero: %w", err)
+	// A target which fails to scale down doesn't keep the others awake
+	var errs []error
+	for _, target := range es.Spec.ScaleTargets() {
+		if err := h.ScaleTargetToIdle(ctx, serviceNamespacedName, target.ScaleTargetRef, es.Spec.IdleReplicas, es.Name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to scale %s %s to idle replicas: %w", target.Kind, target.Name, err))
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func resolveCooldownPeriod(es *v1alpha1.ElastiService) time.Duration {
@@ -239,6 +418,15 @@ func resolveCooldownPeriod(es *v1alpha1.ElastiService) time.Duration {
 }
 
 func (h *ScaleHandler) handleScaleFromZero(ctx context.Context, es *v1alpha1.ElastiService) error {
+	// The dependencies are woken up first, a failure to wake them up doesn't keep the service itself asleep
+	if err := h.WakeDependencies(ctx, es); err != nil {
+		h.logger.Error("Failed to wake up dependencies", zap.String("es", es.Namespace+"/"+es.Name), zap.Error(err))
+	}
+	return h.wakeElastiService(ctx, es)
+}
+
+// wakeElastiService scales the targets of the ElastiService from zero, and resets its cooldown period
+func (h *ScaleHandler) wakeElastiService(ctx context.Context, es *v1alpha1.ElastiService) error {
 	serviceNamespacedName := types.NamespacedName{
 		Name:      es.Spec.Service,
 		Namespace: es.Namespace,
@@ -251,187 +439,162 @@ func (h *ScaleHandler) handleScaleFromZero(ctx context.Context, es *v1alpha1.Ela
 
 	// Unpause the KEDA ScaledObject if it's paused
 	if es.Spec.Autoscaler != nil && strings.ToLower(es.Spec.Autoscaler.Type) == "keda" {
-		err := h.UpdateKedaScaledObjectPausedState(ctx, es.Spec.Autoscaler.Name, es.Namespace, false)
+		err := h.UpdateKedaScaledObjectPausedState(ctx, es.Spec.Autoscaler.Name, es.Namespace, false, 0)
 		if err != nil {
 			return fmt.Errorf("failed to update Keda ScaledObject for service %s: %w", serviceNamespacedName.String(), err)
 		}
 	}
 
-	if err := h.ScaleTargetFromZero(ctx, serviceNamespacedName, es.Spec.ScaleTargetRef.Kind, es.Spec.ScaleTargetRef.Name, es.Spec.MinTargetReplicas, es.Name); err != nil {
-		return fmt.Errorf("failed to scale target from zero: %w", err)
+	if err := h.ScaleTargetsFromZero(ctx, serviceNamespacedName, es.Name, es.Spec, es.Status, 0); err != nil {
+		return fmt.Errorf("failed to scale targets from zero: %w", err)
 	}
 
 	return nil
 }
 
-func (h *ScaleHandler) createScalerForTrigger(trigger *v1alpha1.ScaleTrigger, cooldownPeriod time.Duration) (scalers.Scaler, error) {
-	var scaler scalers.Scaler
-	var err error
-
-	switch trigger.Type {
-	case "prometheus":
-		scaler, err = scalers.NewPrometheusScaler(trigger.Metadata, cooldownPeriod)
-	default:
-		return nil, fmt.Errorf("unsupported trigger type: %s", trigger.Type)
-	}
-
+func (h *ScaleHandler) createScalerForTrigger(ctx context.Context, trigger *v1alpha1.ScaleTrigger, cooldownPeriod time.Duration, es *v1alpha1.ElastiService) (scalers.Scaler, error) {
+	scaler, err := scalers.New(ctx, trigger.Type, &scalers.Config{
+		Name:           es.Name,
+		Namespace:      es.Namespace,
+		Metadata:       trigger.Metadata,
+		CooldownPeriod: cooldownPeriod,
+		KubeClient:     h.kClient,
+		DynamicClient:  h.kDynamicClient,
+		Cache:          h.scalerCache.WithTTL(resolvePollingInterval(es, h.pollingInterval) / 2),
+		MetricStore:    h.metricStore,
+		LeaseStore:     h.leaseStore,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scaler: %w", err)
 	}
 	return scaler, nil
 }
 
-// ScaleTargetFromZero scales the TargetRef to the provided replicas when it's at 0
-func (h *ScaleHandler) ScaleTargetFromZero(ctx context.Context, serviceNamespacedName types.NamespacedName, targetKind, targetName string, replicas int32, elastiServiceName string) error {
-	mutex := h.getMutexForScale(serviceNamespacedName.String())
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	h.logger.Info("Scaling up from zero", zap.String("kind", targetKind), zap.String("namespacedName", serviceNamespacedName.String()), zap.Int32("replicas", replicas))
-
-	var err error
-	// This variable tracks whether the scale target was scaled or not. This is to prevent the scaled up event from being created multiple times.
-	scaled := false
-	switch strings.ToLower(targetKind) {
-	case values.KindDeployments:
-		scaled, err = h.ScaleDeployment(ctx, serviceNamespacedName.Namespace, targetName, replicas)
-	case values.KindRollout:
-		scaled, err = h.ScaleArgoRollout(ctx, serviceNamespacedName.Namespace, targetName, replicas)
-	default:
-		return fmt.Errorf("unsupported target kind: %s", targetKind)
+// WakeReplicas returns the replicas to wake the target up with for the requests queued in the resolver.
+// It is chosen by the wakeReplicasPolicy, unless maxWakeReplicas allows one replica per wakeRequestsPerReplica queued requests.
+func WakeReplicas(spec v1alpha1.ElastiServiceSpec, status v1alpha1.ElastiServiceStatus, queuedRequests int) int32 {
+	replicas := spec.MinTargetReplicas
+	switch spec.WakeReplicasPolicy {
+	case v1alpha1.WakeReplicasPolicyPrevious:
+		if status.ReplicasBeforeIdle > 0 {
+			replicas = status.ReplicasBeforeIdle
+		}
+	case v1alpha1.WakeReplicasPolicyMax:
+		replicas = max(replicas, status.ReplicasBeforeIdle)
 	}
+	return queuedWakeReplicas(spec, replicas, queuedRequests)
+}
 
-	if err != nil {
-		h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Warning", "ScaleFromZeroFailed", fmt.Sprintf("Failed to scale %s from zero to %d replicas: %v", targetKind, replicas, err))
-		return fmt.Errorf("ScaleTargetFromZero - %s: %w", targetKind, err)
+// queuedWakeReplicas returns the replicas needed for the queued requests, at least replicas and at most maxWakeReplicas
+func queuedWakeReplicas(spec v1alpha1.ElastiServiceSpec, replicas int32, queuedRequests int) int32 {
+	if spec.MaxWakeReplicas <= replicas || queuedRequests <= 0 {
+		return replicas
 	}
-
-	if !scaled {
-		// Returning nil as the scale target is already scaled
-		return nil
+	requestsPerReplica := int64(spec.WakeRequestsPerReplica)
+	if requestsPerReplica <= 0 {
+		requestsPerReplica = values.DefaultWakeRequestsPerReplica
 	}
+	needed := (int64(queuedRequests) + requestsPerReplica - 1) / requestsPerReplica
+	return int32(min(max(needed, int64(replicas)), int64(spec.MaxWakeReplicas)))
+}
 
-	h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Normal", "ScaledUpFromZero", fmt.Sprintf("Successfully scaled %s from zero to %d replicas", targetKind, replicas))
+// ScaleTargetsFromZero wakes up all the targets of the ElastiService. The first target is woken up with the WakeReplicas
+// for the requests queued in the resolver, and the others with their minReplicas.
+func (h *ScaleHandler) ScaleTargetsFromZero(ctx context.Context, serviceNamespacedName types.NamespacedName, elastiServiceName string,
+	spec v1alpha1.ElastiServiceSpec, status v1alpha1.ElastiServiceStatus, queuedRequests int) error {
+	targets := spec.ScaleTargets()
+	if len(targets) == 0 {
+		return fmt.Errorf("no scale target for service %s", serviceNamespacedName.String())
+	}
 
-	return nil
+	var errs []error
+	for i, target := range targets {
+		replicas := target.MinReplicas
+		if i == 0 {
+			current, err := h.TargetReplicas(ctx, serviceNamespacedName.Namespace, target.ScaleTargetRef)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get replicas of %s %s: %w", target.Kind, target.Name, err))
+				continue
+			}
+			// The wakeReplicasPolicy only applies to a target at its idle replicas. A target which is already awake,
+			// e.g. by the first request of a burst, is still scaled up for the requests queued since then.
+			if current <= spec.IdleReplicas {
+				replicas = WakeReplicas(spec, status, queuedRequests)
+			} else {
+				replicas = queuedWakeReplicas(spec, replicas, queuedRequests)
+			}
+		}
+		if err := h.ScaleTargetFromZero(ctx, serviceNamespacedName, target.ScaleTargetRef, replicas, elastiServiceName); err != nil {
+			errs = append(errs, fmt.Errorf("failed to scale %s %s from zero: %w", target.Kind, target.Name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// ScaleTargetToZero scales the target to zero
-func (h *ScaleHandler) ScaleTargetToZero(ctx context.Context, serviceNamespacedName types.NamespacedName, targetKind string, targetName string, elastiServiceName string) error {
+// ScaleTargetFromZero scales the TargetRef to the provided replicas when it's at 0
+func (h *ScaleHandler) ScaleTargetFromZero(ctx context.Context, serviceNamespacedName types.NamespacedName, targetRef v1alpha1.ScaleTargetRef, replicas int32, elastiServiceName string) error {
 	mutex := h.getMutexForScale(serviceNamespacedName.String())
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	h.logger.Info("Scaling down to zero", zap.String("kind", targetKind), zap.String("namespacedName", serviceNamespacedName.String()))
+	h.logger.Info("Scaling up from zero", zap.String("kind", targetRef.Kind), zap.String("namespacedName", serviceNamespacedName.String()), zap.Int32("replicas", replicas))
 
-	var err error
-	scaled := false
-	switch strings.ToLower(targetKind) {
-	case values.KindDeployments:
-		scaled, err = h.ScaleDeployment(ctx, serviceNamespacedName.Namespace, targetName, 0)
-	case values.KindRollout:
-		scaled, err = h.ScaleArgoRollout(ctx, serviceNamespacedName.Namespace, targetName, 0)
-	default:
-		return fmt.Errorf("unsupported target kind: %s", targetKind)
-	}
+	// This variable tracks whether the scale target was scaled or not. This is to prevent the scaled up event from being created multiple times.
+	scaled, err := h.scaleTarget(ctx, serviceNamespacedName.Namespace, targetRef, replicas, ScaleUp)
 
 	if err != nil {
-		h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Warning", "ScaleToZeroFailed", fmt.Sprintf("Failed to scale %s to zero: %v", targetKind, err))
-		return fmt.Errorf("ScaleTargetToZero - %s: %w", targetKind, err)
+		h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Warning", "ScaleFromZeroFailed", fmt.Sprintf("Failed to scale %s from zero to %d replicas: %v", targetRef.Kind, replicas, err))
+		return fmt.Errorf("ScaleTargetFromZero - %s: %w", targetRef.Kind, err)
 	}
 
 	if !scaled {
-		// Returning nil as the scale target is already scaled down to zero
+		// Returning nil as the scale target is already scaled
 		return nil
 	}
 
-	h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Normal", "ScaledDownToZero", fmt.Sprintf("Successfully scaled %s to zero", targetKind))
+	h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Normal", "ScaledUpFromZero", fmt.Sprintf("Successfully scaled %s from zero to %d replicas", targetRef.Kind, replicas))
 
 	return nil
 }
 
-// ScaleDeployment scales the deployment to the provided replicas
-// TODO: use a generic logic to perform scaling similar to HPA/KEDA
-func (h *ScaleHandler) ScaleDeployment(ctx context.Context, namespace, targetName string, replicas int32) (bool, error) {
-	deploymentClient := h.kClient.AppsV1().Deployments(namespace)
-	deploy, err := deploymentClient.Get(ctx, targetName, metav1.GetOptions{})
-	if err != nil {
-		return false, fmt.Errorf("ScaleDeployment - GET: %w", err)
-	}
+// ScaleTargetToIdle scales the target down to its idle replicas, which is zero unless the ElastiService sets idleReplicas
+func (h *ScaleHandler) ScaleTargetToIdle(ctx context.Context, serviceNamespacedName types.NamespacedName, targetRef v1alpha1.ScaleTargetRef, replicas int32, elastiServiceName string) error {
+	mutex := h.getMutexForScale(serviceNamespacedName.String())
+	mutex.Lock()
+	defer mutex.Unlock()
 
-	h.logger.Debug("Deployment found", zap.String("deployment", targetName), zap.Int32("current replicas", *deploy.Spec.Replicas), zap.Int32("desired replicas", replicas))
-	if deploy.Spec.Replicas == nil {
-		return false, fmt.Errorf("ScaleDeployment - no replicas found for deployment %s", targetName)
-	}
-	if *deploy.Spec.Replicas == replicas {
-		h.logger.Info("Deployment already scaled", zap.String("deployment", targetName), zap.Int32("current replicas", *deploy.Spec.Replicas))
-		return false, nil
-	}
-	if replicas > 0 && *deploy.Spec.Replicas > replicas {
-		h.logger.Info(
-			"Deployment already scaled beyond desired replicas",
-			zap.String("deployment", targetName),
-			zap.Int32("current replicas", *deploy.Spec.Replicas),
-			zap.Int32("desired replicas", replicas),
-		)
-		return false, nil
-	}
+	h.logger.Info("Scaling down to idle replicas", zap.String("kind", targetRef.Kind), zap.String("namespacedName", serviceNamespacedName.String()), zap.Int32("replicas", replicas))
 
-	patchBytes := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
-	_, err = deploymentClient.Patch(ctx, targetName, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
-	if err != nil {
-		return false, fmt.Errorf("ScaleDeployment - Patch: %w", err)
-	}
-	h.logger.Info("Deployment scaled", zap.String("deployment", targetName), zap.Int32("replicas", replicas))
-	return true, nil
-}
+	scaled, err := h.scaleTarget(ctx, serviceNamespacedName.Namespace, targetRef, replicas, ScaleDown)
 
-// ScaleArgoRollout scales the rollout to the provided replicas
-func (h *ScaleHandler) ScaleArgoRollout(ctx context.Context, namespace, targetName string, replicas int32) (bool, error) {
-	rollout, err := h.kDynamicClient.Resource(values.RolloutGVR).Namespace(namespace).Get(ctx, targetName, metav1.GetOptions{})
 	if err != nil {
-		return false, fmt.Errorf("ScaleArgoRollout - GET: %w", err)
+		h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Warning", "ScaleToIdleFailed", fmt.Sprintf("Failed to scale %s to %d idle replicas: %v", targetRef.Kind, replicas, err))
+		return fmt.Errorf("ScaleTargetToIdle - %s: %w", targetRef.Kind, err)
 	}
 
-	if rollout.Object["spec"] == nil || rollout.Object["spec"].(map[string]interface{})["replicas"] == nil {
-		return false, fmt.Errorf("ScaleArgoRollout - no replicas found for rollout %s", targetName)
-	}
-	currentReplicas := rollout.Object["spec"].(map[string]interface{})["replicas"].(int64)
-	h.logger.Info("Rollout found", zap.String("rollout", targetName), zap.Int64("current replicas", currentReplicas), zap.Int32("desired replicas", replicas))
-
-	if currentReplicas == int64(replicas) {
-		h.logger.Info("Rollout already scaled", zap.String("rollout", targetName), zap.Int64("current replicas", currentReplicas))
-		return false, nil
+	if !scaled {
+		// Returning nil as the scale target is already scaled down to its idle replicas
+		return nil
 	}
 
-	if replicas > 0 && currentReplicas > int64(replicas) {
-		h.logger.Info("Rollout already scaled beyond desired replicas", zap.String("rollout", targetName), zap.Int64("current replicas", currentReplicas), zap.Int32("desired replicas", replicas))
-		return false, nil
+	if replicas == 0 {
+		h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Normal", "ScaledDownToZero", fmt.Sprintf("Successfully scaled %s to zero", targetRef.Kind))
+	} else {
+		h.createEvent(serviceNamespacedName.Namespace, elastiServiceName, "Normal", "ScaledDownToIdle", fmt.Sprintf("Successfully scaled %s to %d idle replicas", targetRef.Kind, replicas))
 	}
 
-	patchBytes := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
-	_, err = h.kDynamicClient.Resource(values.RolloutGVR).Namespace(namespace).Patch(
-		ctx,
-		targetName,
-		types.MergePatchType,
-		patchBytes,
-		metav1.PatchOptions{},
-	)
-	if err != nil {
-		return false, fmt.Errorf("ScaleArgoRollout - Patch: %w", err)
-	}
-	h.logger.Info("Rollout scaled", zap.String("rollout", targetName), zap.Int32("replicas", replicas))
-	return true, nil
+	return nil
 }
 
-func (h *ScaleHandler) UpdateKedaScaledObjectPausedState(ctx context.Context, scaledObjectName, namespace string, paused bool) error {
+func (h *ScaleHandler) UpdateKedaScaledObjectPausedState(ctx context.Context, scaledObjectName, namespace string, paused bool, pausedReplicas int32) error {
 	var patchBytes []byte
 	if paused {
-		// When pausing, set both annotations: paused=true and paused-replicas="0"
-		patchBytes = []byte(fmt.Sprintf(`{"metadata": {"annotations": {"%s": "%s", "%s": "0"}}}`,
+		// When pausing, set both annotations: paused=true and paused-replicas to the idle replicas
+		patchBytes = []byte(fmt.Sprintf(`{"metadata": {"annotations": {"%s": "%s", "%s": "%d"}}}`,
 			kedaPausedAnnotation,
 			strconv.FormatBool(paused),
-			kedaPausedReplicasAnnotation))
+			kedaPausedReplicasAnnotation,
+			pausedReplicas))
 	} else {
 		// When unpausing, set paused=false and remove the paused-replicas annotation
 		patchBytes = []byte(fmt.Sprintf(`{"metadata": {"annotations": {"%s": "%s", "%s": null}}}`,
@@ -466,6 +629,71 @@ func (h *ScaleHandler) UpdateLastScaledUpTime(ctx context.Context, crdName, name
 	return nil
 }
 
+// recordReplicasBeforeIdle records the current replicas of the first target in the status of the ElastiService,
+// when the target is about to be scaled down from them
+func (h *ScaleHandler) recordReplicasBeforeIdle(ctx context.Context, es *v1alpha1.ElastiService) error {
+	targets := es.Spec.ScaleTargets()
+	if len(targets) == 0 {
+		return nil
+	}
+	replicas, err := h.TargetReplicas(ctx, es.Namespace, targets[0].ScaleTargetRef)
+	if err != nil {
+		return err
+	}
+	if replicas <= es.Spec.IdleReplicas || replicas == es.Status.ReplicasBeforeIdle {
+		return nil
+	}
+
+	patchBytes := []byte(fmt.Sprintf(`{"status": {"replicasBeforeIdle": %d}}`, replicas))
+	_, err = h.kDynamicClient.Resource(values.ElastiServiceGVR).
+		Namespace(es.Namespace).
+		Patch(ctx, es.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("failed to patch ElastiService status: %w", err)
+	}
+	return nil
+}
+
+func (h *ScaleHandler) UpdateConsecutiveIdleEvaluations(ctx context.Context, crdName, namespace string, count int32) error {
+	patchBytes := []byte(fmt.Sprintf(`{"status": {"consecutiveIdleEvaluations": %d}}`, count))
+
+	_, err := h.kDynamicClient.Resource(values.ElastiServiceGVR).
+		Namespace(namespace).
+		Patch(ctx, crdName, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("failed to patch ElastiService status: %w", err)
+	}
+	return nil
+}
+
+// updateTriggerEvaluation records the evaluation in the status of the ElastiService when its outcome changed
+func (h *ScaleHandler) updateTriggerEvaluation(ctx context.Context, es *v1alpha1.ElastiService, evaluation *v1alpha1.TriggerEvaluation) error {
+	if previous := es.Status.TriggerEvaluation; previous != nil {
+		evaluation.LastTransitionTime = previous.LastTransitionTime
+		if equality.Semantic.DeepEqual(previous, evaluation) {
+			return nil
+		}
+	}
+	evaluation.LastTransitionTime = metav1.Now()
+
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"triggerEvaluation": evaluation,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal trigger evaluation: %w", err)
+	}
+
+	_, err = h.kDynamicClient.Resource(values.ElastiServiceGVR).
+		Namespace(es.Namespace).
+		Patch(ctx, es.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("failed to patch ElastiService status: %w", err)
+	}
+	return nil
+}
+
 // createEvent creates a new event on scaling up or down
 func (h *ScaleHandler) createEvent(namespace, name, eventType, reason, message string) {
 	h.logger.Info("createEvent", zap.String("eventType", eventType), zap.String("reason", reason), zap.String("message", message))
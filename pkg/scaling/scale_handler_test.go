@@ -0,0 +1,198 @@
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/truefoundry/elasti/pkg/values"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAlreadyScaledBeyond(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   int64
+		replicas  int32
+		direction ScaleDirection
+		expected  bool
+	}{
+		{name: "Scale up from zero", current: 0, replicas: 2, direction: ScaleUp, expected: false},
+		{name: "Scale up from idle replicas", current: 1, replicas: 2, direction: ScaleUp, expected: false},
+		{name: "Already scaled up by an autoscaler", current: 5, replicas: 2, direction: ScaleUp, expected: true},
+		{name: "Scale down to zero", current: 5, replicas: 0, direction: ScaleDown, expected: false},
+		{name: "Scale down to idle replicas", current: 5, replicas: 1, direction: ScaleDown, expected: false},
+		{name: "Below the idle replicas", current: 0, replicas: 1, direction: ScaleDown, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, alreadyScaledBeyond(tt.current, tt.replicas, tt.direction))
+		})
+	}
+}
+
+func TestWakeReplicas(t *testing.T) {
+	tests := []struct {
+		name           string
+		spec           v1alpha1.ElastiServiceSpec
+		status         v1alpha1.ElastiServiceStatus
+		queuedRequests int
+		expected       int32
+	}{
+		{
+			name:           "No maxWakeReplicas",
+			spec:           v1alpha1.ElastiServiceSpec{MinTargetReplicas: 1},
+			queuedRequests: 500,
+			expected:       1,
+		},
+		{
+			name:           "Small backlog",
+			spec:           v1alpha1.ElastiServiceSpec{MinTargetReplicas: 2, MaxWakeReplicas: 10, WakeRequestsPerReplica: 50},
+			queuedRequests: 20,
+			expected:       2,
+		},
+		{
+			name:           "Backlog spread across replicas",
+			spec:           v1alpha1.ElastiServiceSpec{MinTargetReplicas: 1, MaxWakeReplicas: 10, WakeRequestsPerReplica: 50},
+			queuedRequests: 101,
+			expected:       3,
+		},
+		{
+			name:           "Backlog beyond maxWakeReplicas",
+			spec:           v1alpha1.ElastiServiceSpec{MinTargetReplicas: 1, MaxWakeReplicas: 5, WakeRequestsPerReplica: 50},
+			queuedRequests: 500,
+			expected:       5,
+		},
+		{
+			name:           "Default requests per replica",
+			spec:           v1alpha1.ElastiServiceSpec{MinTargetReplicas: 1, MaxWakeReplicas: 5},
+			queuedRequests: 25,
+			expected:       3,
+		},
+		{
+			name:           "No queued requests",
+			spec:           v1alpha1.ElastiServiceSpec{MinTargetReplicas: 1, MaxWakeReplicas: 5},
+			queuedRequests: 0,
+			expected:       1,
+		},
+		{
+			name:     "Min policy",
+			spec:     v1alpha1.ElastiServiceSpec{MinTargetReplicas: 1, WakeReplicasPolicy: v1alpha1.WakeReplicasPolicyMin},
+			status:   v1alpha1.ElastiServiceStatus{ReplicasBeforeIdle: 8},
+			expected: 1,
+		},
+		{
+			name:     "Previous policy",
+			spec:     v1alpha1.ElastiServiceSpec{MinTargetReplicas: 2, WakeReplicasPolicy: v1alpha1.WakeReplicasPolicyPrevious},
+			status:   v1alpha1.ElastiServiceStatus{ReplicasBeforeIdle: 1},
+			expected: 1,
+		},
+		{
+			name:     "Previous policy without previous replicas",
+			spec:     v1alpha1.ElastiServiceSpec{MinTargetReplicas: 2, WakeReplicasPolicy: v1alpha1.WakeReplicasPolicyPrevious},
+			expected: 2,
+		},
+		{
+			name:     "Max policy",
+			spec:     v1alpha1.ElastiServiceSpec{MinTargetReplicas: 2, WakeReplicasPolicy: v1alpha1.WakeReplicasPolicyMax},
+			status:   v1alpha1.ElastiServiceStatus{ReplicasBeforeIdle: 8},
+			expected: 8,
+		},
+		{
+			name:           "Max policy with a larger backlog",
+			spec:           v1alpha1.ElastiServiceSpec{MinTargetReplicas: 2, MaxWakeReplicas: 20, WakeRequestsPerReplica: 10, WakeReplicasPolicy: v1alpha1.WakeReplicasPolicyMax},
+			status:         v1alpha1.ElastiServiceStatus{ReplicasBeforeIdle: 8},
+			queuedRequests: 150,
+			expected:       15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WakeReplicas(tt.spec, tt.status, tt.queuedRequests))
+		})
+	}
+}
+
+func TestHandleScaleToZeroRecordsReplicasBeforePausingKeda(t *testing.T) {
+	replicas := map[string]int32{"api": 3}
+	h := newFakeScaleHandler(replicas, 0, new(int))
+	dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{values.ElastiServiceGVR: "ElastiServiceList"})
+	var actions []string
+	dynamicClient.PrependReactor("patch", "scaledobjects", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		actions = append(actions, "pause keda")
+		// KEDA scales the target down to the paused replicas as soon as it's paused
+		replicas["api"] = 0
+		return true, &unstructured.Unstructured{}, nil
+	})
+	dynamicClient.PrependReactor("patch", "elastiservices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		actions = append(actions, string(action.(k8stesting.PatchAction).GetPatch()))
+		return true, &unstructured.Unstructured{}, nil
+	})
+	h.kDynamicClient = dynamicClient
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runEvaluationQueue(ctx, t, h)
+
+	es := newDependentElastiService("api", "api")
+	es.Spec.Autoscaler = &v1alpha1.AutoscalerSpec{Type: "keda", Name: "api"}
+	require.NoError(t, h.handleScaleToZero(ctx, time.Minute, es))
+	assert.Equal(t, []string{`{"status": {"replicasBeforeIdle": 3}}`, "pause keda"}, actions)
+	assert.Equal(t, int32(0), replicas["api"])
+}
+
+func TestEvaluateElastiServiceSkipsInvalidTriggers(t *testing.T) {
+	// The handler has no clients, so any evaluation of the triggers would fail
+	h := newFakeScaleHandler(map[string]int32{}, 0, new(int))
+	es := newDependentElastiService("api", "api")
+	es.Spec.Triggers = []v1alpha1.ScaleTrigger{{Type: "my-queue"}}
+	assert.NoError(t, h.evaluateElastiService(context.Background(), es))
+}
+
+func TestApplyIdleEvaluations(t *testing.T) {
+	tests := []struct {
+		name            string
+		count           int32
+		mode            string
+		direction       ScaleDirection
+		expected        ScaleDirection
+		expectedPatches []string
+	}{
+		{name: "First idle evaluation", count: 0, direction: ScaleDown, expected: NoScale, expectedPatches: []string{`{"status": {"consecutiveIdleEvaluations": 1}}`}},
+		{name: "Enough idle evaluations", count: 2, direction: ScaleDown, expected: ScaleDown, expectedPatches: []string{`{"status": {"consecutiveIdleEvaluations": 3}}`}},
+		{name: "Idle after enough idle evaluations", count: 3, direction: ScaleDown, expected: ScaleDown},
+		{name: "Active evaluation", count: 2, direction: ScaleUp, expected: ScaleUp, expectedPatches: []string{`{"status": {"consecutiveIdleEvaluations": 0}}`}},
+		{name: "Unhealthy evaluation", count: 2, direction: NoScale, expected: NoScale},
+		{name: "Already at zero", count: 0, mode: values.ProxyMode, direction: ScaleDown, expected: ScaleDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newFakeScaleHandler(map[string]int32{}, 0, new(int))
+			dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{values.ElastiServiceGVR: "ElastiServiceList"})
+			var patches []string
+			dynamicClient.PrependReactor("patch", "elastiservices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				patches = append(patches, string(action.(k8stesting.PatchAction).GetPatch()))
+				return true, &unstructured.Unstructured{}, nil
+			})
+			h.kDynamicClient = dynamicClient
+
+			es := newDependentElastiService("api", "api")
+			es.Spec.MinIdleEvaluations = 3
+			es.Status.ConsecutiveIdleEvaluations = tt.count
+			es.Status.Mode = tt.mode
+			assert.Equal(t, tt.expected, h.applyIdleEvaluations(context.Background(), es, tt.direction))
+			assert.Equal(t, tt.expectedPatches, patches)
+		})
+	}
+}
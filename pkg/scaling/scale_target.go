@@ -0,0 +1,107 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+)
+
+// TargetResource resolves the resource of the scale target with the RESTMapper. The kind of the target is either
+// its kind or its resource name, e.g. Deployment, deployments or inferenceservices, and resolves to its plural
+// resource even when it is irregular, e.g. NetworkPolicy to networkpolicies.
+func (h *ScaleHandler) TargetResource(ref v1alpha1.ScaleTargetRef) (schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("failed to parse API version %s: %w", ref.APIVersion, err)
+	}
+	partial := gv.WithResource(strings.ToLower(ref.Kind))
+
+	gvr, err := h.restMapper.ResourceFor(partial)
+	if meta.IsNoMatchError(err) {
+		// The resource may have been installed after the discovery information was cached
+		if mapper, ok := h.restMapper.(meta.ResettableRESTMapper); ok {
+			mapper.Reset()
+			gvr, err = h.restMapper.ResourceFor(partial)
+		}
+	}
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("failed to find resource %s: %w", partial, err)
+	}
+	return gvr, nil
+}
+
+// scaleTarget scales the target to the provided replicas through its scale subresource, a target already beyond them
+// in the direction is left as it is. The update is conditioned on the resourceVersion of the scale it was computed from,
+// so it is retried on a fresh read when the replicas were changed in between, e.g. by an autoscaler.
+func (h *ScaleHandler) scaleTarget(ctx context.Context, namespace string, ref v1alpha1.ScaleTargetRef, replicas int32, direction ScaleDirection) (bool, error) {
+	gvr, err := h.TargetResource(ref)
+	if err != nil {
+		return false, err
+	}
+	groupResource := gvr.GroupResource()
+
+	scaled := false
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		scale, err := h.scaleClient.Scales(namespace).Get(ctx, groupResource, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get scale of %s %s: %w", groupResource, ref.Name, err)
+		}
+
+		current := scale.Spec.Replicas
+		h.logger.Debug("Scale target found", zap.String("resource", groupResource.String()), zap.String("name", ref.Name), zap.Int32("current replicas", current), zap.Int32("desired replicas", replicas))
+		if current == replicas {
+			h.logger.Info("Scale target already scaled", zap.String("resource", groupResource.String()), zap.String("name", ref.Name), zap.Int32("current replicas", current))
+			return nil
+		}
+		if alreadyScaledBeyond(int64(current), replicas, direction) {
+			h.logger.Info("Scale target already scaled beyond desired replicas",
+				zap.String("resource", groupResource.String()),
+				zap.String("name", ref.Name),
+				zap.Int32("current replicas", current),
+				zap.Int32("desired replicas", replicas))
+			return nil
+		}
+
+		scale.Spec.Replicas = replicas
+		if _, err := h.scaleClient.Scales(namespace).Update(ctx, groupResource, scale, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		scaled = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to scale %s %s: %w", groupResource, ref.Name, err)
+	}
+	if scaled {
+		h.logger.Info("Scale target scaled", zap.String("resource", groupResource.String()), zap.String("name", ref.Name), zap.Int32("replicas", replicas))
+	}
+	return scaled, nil
+}
+
+// TargetReplicas returns the desired replicas of the target from its scale subresource
+func (h *ScaleHandler) TargetReplicas(ctx context.Context, namespace string, ref v1alpha1.ScaleTargetRef) (int32, error) {
+	gvr, err := h.TargetResource(ref)
+	if err != nil {
+		return 0, err
+	}
+	groupResource := gvr.GroupResource()
+	scale, err := h.scaleClient.Scales(namespace).Get(ctx, groupResource, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get scale of %s %s: %w", groupResource, ref.Name, err)
+	}
+	return scale.Spec.Replicas, nil
+}
+
+// alreadyScaledBeyond reports whether the target is already past the desired replicas, e.g. scaled up further
+// by an autoscaler when it is woken up. A target below its idle replicas, e.g. found at 0 after a rollout or
+// a manual scale, isn't past them and is scaled up to them.
+func alreadyScaledBeyond(current int64, replicas int32, direction ScaleDirection) bool {
+	return direction == ScaleUp && current > int64(replicas)
+}
@@ -0,0 +1,190 @@
+package scaling
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/scale/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+var modelServersGVR = schema.GroupVersionResource{Group: "serving.example.com", Version: "v1", Resource: "modelservers"}
+
+// newFakeScaleHandler returns a ScaleHandler whose modelservers have the replicas, and which fails the first
+// conflicts updates of their scale with a conflict
+func newFakeScaleHandler(replicas map[string]int32, conflicts int, updates *int) *ScaleHandler {
+	restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{modelServersGVR.GroupVersion()})
+	restMapper.Add(modelServersGVR.GroupVersion().WithKind("ModelServer"), meta.RESTScopeNamespace)
+
+	resourceVersion := 1
+	scaleClient := &fake.FakeScaleClient{}
+	scaleClient.AddReactor("get", "modelservers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		name := action.(k8stesting.GetAction).GetName()
+		current, ok := replicas[name]
+		if !ok {
+			return true, nil, errors.NewNotFound(modelServersGVR.GroupResource(), name)
+		}
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", ResourceVersion: strconv.Itoa(resourceVersion)},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: current},
+		}, nil
+	})
+	scaleClient.AddReactor("update", "modelservers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*updates++
+		if conflicts > 0 {
+			conflicts--
+			// Another controller changed the replicas in between
+			resourceVersion++
+			return true, nil, errors.NewConflict(modelServersGVR.GroupResource(), "llama", nil)
+		}
+		scale := action.(k8stesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+		replicas[scale.Name] = scale.Spec.Replicas
+		return true, scale, nil
+	})
+
+	return &ScaleHandler{logger: zap.NewNop(), scaleClient: scaleClient, restMapper: restMapper, EventRecorder: record.NewFakeRecorder(100)}
+}
+
+func TestScaleTarget(t *testing.T) {
+	ref := v1alpha1.ScaleTargetRef{APIVersion: "serving.example.com/v1", Kind: "modelservers", Name: "llama"}
+
+	tests := []struct {
+		name           string
+		current        int32
+		replicas       int32
+		direction      ScaleDirection
+		conflicts      int
+		expectScaled   bool
+		expectReplicas int32
+		expectUpdates  int
+		expectConflict bool
+	}{
+		{name: "Scale up from zero", current: 0, replicas: 2, direction: ScaleUp, expectScaled: true, expectReplicas: 2, expectUpdates: 1},
+		{name: "Scale down to zero", current: 3, replicas: 0, direction: ScaleDown, expectScaled: true, expectReplicas: 0, expectUpdates: 1},
+		{name: "Scale up to idle replicas from zero", current: 0, replicas: 1, direction: ScaleDown, expectScaled: true, expectReplicas: 1, expectUpdates: 1},
+		{name: "Already scaled", current: 2, replicas: 2, direction: ScaleUp, expectScaled: false, expectReplicas: 2},
+		{name: "Already scaled up by an autoscaler", current: 5, replicas: 2, direction: ScaleUp, expectScaled: false, expectReplicas: 5},
+		{name: "Retried on a conflict", current: 0, replicas: 2, direction: ScaleUp, conflicts: 2, expectScaled: true, expectReplicas: 2, expectUpdates: 3},
+		{name: "Too many conflicts", current: 0, replicas: 2, direction: ScaleUp, conflicts: 10, expectReplicas: 0, expectUpdates: 5, expectConflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replicas := map[string]int32{"llama": tt.current}
+			updates := 0
+			h := newFakeScaleHandler(replicas, tt.conflicts, &updates)
+
+			scaled, err := h.scaleTarget(context.Background(), "shop", ref, tt.replicas, tt.direction)
+			if tt.expectConflict {
+				assert.True(t, errors.IsConflict(err))
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectScaled, scaled)
+			assert.Equal(t, tt.expectReplicas, replicas["llama"])
+			assert.Equal(t, tt.expectUpdates, updates)
+
+			current, err := h.TargetReplicas(context.Background(), "shop", ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectReplicas, current)
+		})
+	}
+
+	// Resources unknown to the RESTMapper can't be scaled
+	h := newFakeScaleHandler(map[string]int32{"llama": 0}, 0, new(int))
+	_, err := h.scaleTarget(context.Background(), "shop", v1alpha1.ScaleTargetRef{APIVersion: "serving.example.com/v1", Kind: "unknowns", Name: "llama"}, 1, ScaleUp)
+	assert.Error(t, err)
+}
+
+func TestTargetResource(t *testing.T) {
+	networkPoliciesGVR := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}
+	restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{modelServersGVR.GroupVersion(), networkPoliciesGVR.GroupVersion()})
+	restMapper.Add(modelServersGVR.GroupVersion().WithKind("ModelServer"), meta.RESTScopeNamespace)
+	restMapper.Add(networkPoliciesGVR.GroupVersion().WithKind("NetworkPolicy"), meta.RESTScopeNamespace)
+	h := &ScaleHandler{logger: zap.NewNop(), restMapper: restMapper}
+
+	tests := []struct {
+		name        string
+		ref         v1alpha1.ScaleTargetRef
+		expectGVR   schema.GroupVersionResource
+		expectError bool
+	}{
+		{name: "Resource", ref: v1alpha1.ScaleTargetRef{APIVersion: "serving.example.com/v1", Kind: "modelservers"}, expectGVR: modelServersGVR},
+		{name: "Kind", ref: v1alpha1.ScaleTargetRef{APIVersion: "serving.example.com/v1", Kind: "ModelServer"}, expectGVR: modelServersGVR},
+		{name: "Irregular plural", ref: v1alpha1.ScaleTargetRef{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"}, expectGVR: networkPoliciesGVR},
+		{name: "Unknown resource", ref: v1alpha1.ScaleTargetRef{APIVersion: "serving.example.com/v1", Kind: "unknowns"}, expectError: true},
+		{name: "Invalid API version", ref: v1alpha1.ScaleTargetRef{APIVersion: "serving/example/v1", Kind: "modelservers"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gvr, err := h.TargetResource(tt.ref)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectGVR, gvr)
+		})
+	}
+}
+
+func TestScaleTargetsFromZero(t *testing.T) {
+	target := func(name string, minReplicas int32) v1alpha1.ScaleTarget {
+		return v1alpha1.ScaleTarget{
+			ScaleTargetRef: v1alpha1.ScaleTargetRef{APIVersion: "serving.example.com/v1", Kind: "modelservers", Name: name},
+			MinReplicas:    minReplicas,
+		}
+	}
+	spec := v1alpha1.ElastiServiceSpec{
+		ScaleTargetRefs:        []v1alpha1.ScaleTarget{target("api", 0), target("worker", 0), target("frontend", 3)},
+		MinTargetReplicas:      1,
+		MaxWakeReplicas:        4,
+		WakeRequestsPerReplica: 10,
+	}
+	serviceNamespacedName := types.NamespacedName{Namespace: "shop", Name: "api"}
+
+	// The first target is sized for the queued requests, the others are woken up with their minReplicas
+	replicas := map[string]int32{"api": 0, "worker": 0, "frontend": 0}
+	h := newFakeScaleHandler(replicas, 0, new(int))
+	require.NoError(t, h.ScaleTargetsFromZero(context.Background(), serviceNamespacedName, "api-elasti", spec, v1alpha1.ElastiServiceStatus{}, 25))
+	assert.Equal(t, map[string]int32{"api": 3, "worker": 1, "frontend": 3}, replicas)
+
+	// A target already woken up to its minReplicas is scaled up for a larger backlog, but the policy doesn't apply to it
+	replicas = map[string]int32{"api": 1, "worker": 1, "frontend": 3}
+	h = newFakeScaleHandler(replicas, 0, new(int))
+	previous := spec
+	previous.WakeReplicasPolicy = v1alpha1.WakeReplicasPolicyPrevious
+	require.NoError(t, h.ScaleTargetsFromZero(context.Background(), serviceNamespacedName, "api-elasti", previous, v1alpha1.ElastiServiceStatus{ReplicasBeforeIdle: 2}, 0))
+	assert.Equal(t, map[string]int32{"api": 1, "worker": 1, "frontend": 3}, replicas)
+	require.NoError(t, h.ScaleTargetsFromZero(context.Background(), serviceNamespacedName, "api-elasti", previous, v1alpha1.ElastiServiceStatus{ReplicasBeforeIdle: 2}, 500))
+	assert.Equal(t, map[string]int32{"api": 4, "worker": 1, "frontend": 3}, replicas)
+
+	// A smaller backlog doesn't scale an awake target down
+	require.NoError(t, h.ScaleTargetsFromZero(context.Background(), serviceNamespacedName, "api-elasti", spec, v1alpha1.ElastiServiceStatus{}, 15))
+	assert.Equal(t, map[string]int32{"api": 4, "worker": 1, "frontend": 3}, replicas)
+
+	// A missing target doesn't keep the others asleep
+	replicas = map[string]int32{"api": 0, "frontend": 0}
+	h = newFakeScaleHandler(replicas, 0, new(int))
+	err := h.ScaleTargetsFromZero(context.Background(), serviceNamespacedName, "api-elasti", spec, v1alpha1.ElastiServiceStatus{}, 0)
+	assert.ErrorContains(t, err, "worker")
+	assert.Equal(t, map[string]int32{"api": 1, "frontend": 3}, replicas)
+
+	// An ElastiService needs a target
+	err = h.ScaleTargetsFromZero(context.Background(), serviceNamespacedName, "api-elasti", v1alpha1.ElastiServiceSpec{}, v1alpha1.ElastiServiceStatus{}, 0)
+	assert.Error(t, err)
+}
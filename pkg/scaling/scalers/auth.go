@@ -0,0 +1,206 @@
+package scalers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Well known keys read from the Secrets referenced in trigger metadata.
+// They match the keys used by the kubernetes.io/basic-auth and kubernetes.io/tls Secret types.
+const (
+	secretKeyUsername    = "username"
+	secretKeyPassword    = "password"
+	secretKeyBearerToken = "bearerToken"
+	secretKeyCA          = "ca.crt"
+	secretKeyCert        = "tls.crt"
+	secretKeyKey         = "tls.key"
+)
+
+// authMetadata is embedded in the metadata of scalers which talk to an external system
+// The referenced Secrets must live in the namespace of the ElastiService
+type authMetadata struct {
+	// AuthSecretRef is the name of a Secret holding the username and password, or a bearer token for HTTP APIs
+	AuthSecretRef string `json:"authSecretRef"`
+	// TLSSecretRef is the name of a Secret holding the CA and/or the client certificate and key
+	TLSSecretRef string `json:"tlsSecretRef"`
+	// EnableTLS enables TLS with the system CAs when no TLSSecretRef is provided
+	EnableTLS bool `json:"enableTLS,string"`
+}
+
+// authConfig is the resolved authentication config for a scaler
+type authConfig struct {
+	username    string
+	password    string
+	bearerToken string
+	tlsConfig   *tls.Config
+	// tlsConfigKey identifies the TLS configuration, so transports can be shared between scalers
+	tlsConfigKey string
+}
+
+// httpMetadata is embedded in the metadata of scalers which talk to an HTTP API
+type httpMetadata struct {
+	authMetadata
+	// Headers are added to every request, e.g. X-Scope-OrgID for multi-tenant Prometheus compatible APIs
+	Headers map[string]string `json:"headers"`
+}
+
+// headersKey identifies the headers, so results are only shared between scalers sending the same headers
+func (m *httpMetadata) headersKey() string {
+	headers := make([]string, 0, len(m.Headers))
+	for key, value := range m.Headers {
+		headers = append(headers, key+"="+value)
+	}
+	sort.Strings(headers)
+	return strings.Join(headers, ",")
+}
+
+// resolveAuthConfig reads the Secrets referenced by the metadata through the cache, so they aren't read
+// from the API server every time the scaler is created
+func resolveAuthConfig(ctx context.Context, kClient kubernetes.Interface, namespace string, metadata *authMetadata, cache *Cache) (*authConfig, error) {
+	config := &authConfig{}
+
+	if metadata.AuthSecretRef != "" {
+		data, err := getSecretData(ctx, kClient, namespace, metadata.AuthSecretRef, cache)
+		if err != nil {
+			return nil, err
+		}
+		config.username = string(data[secretKeyUsername])
+		config.password = string(data[secretKeyPassword])
+		config.bearerToken = string(data[secretKeyBearerToken])
+	}
+
+	if metadata.TLSSecretRef != "" {
+		data, err := getSecretData(ctx, kClient, namespace, metadata.TLSSecretRef, cache)
+		if err != nil {
+			return nil, err
+		}
+		config.tlsConfig, err = newTLSConfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS secret %s/%s: %w", namespace, metadata.TLSSecretRef, err)
+		}
+		config.tlsConfigKey = hashValues(string(data[secretKeyCA]), string(data[secretKeyCert]), string(data[secretKeyKey]))
+	} else if metadata.EnableTLS {
+		config.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		config.tlsConfigKey = "system"
+	}
+
+	return config, nil
+}
+
+// cacheKey identifies the credentials, so results are only shared between scalers using the same credentials
+func (c *authConfig) cacheKey() string {
+	return hashValues(c.username, c.password, c.bearerToken, c.tlsConfigKey)
+}
+
+func hashValues(values ...string) string {
+	hash := sha256.New()
+	for _, value := range values {
+		hash.Write([]byte(value))
+		// Separate the values so that ("ab", "c") and ("a", "bc") don't collide
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// getSecretData returns the data of the Secret, it is reused through the cache for the ttl of the cache
+func getSecretData(ctx context.Context, kClient kubernetes.Interface, namespace, name string, cache *Cache) (map[string][]byte, error) {
+	if kClient == nil {
+		return nil, fmt.Errorf("unable to read secret %s/%s: no kubernetes client", namespace, name)
+	}
+	return cache.getSecret(namespace, name, func() (map[string][]byte, error) {
+		secret, err := kClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+		}
+		return secret.Data, nil
+	})
+}
+
+// newTLSConfig creates a TLS config from the CA and client certificate present in the Secret data
+func newTLSConfig(data map[string][]byte) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if ca, ok := data[secretKeyCA]; ok {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse %s", secretKeyCA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	cert, hasCert := data[secretKeyCert]
+	key, hasKey := data[secretKeyKey]
+	if hasCert != hasKey {
+		return nil, fmt.Errorf("both %s and %s are required for client authentication", secretKeyCert, secretKeyKey)
+	}
+	if hasCert {
+		clientCert, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newHTTPClient creates an HTTP client which authenticates and adds the headers to every request
+// A bearer token takes precedence over the username and password
+// The transport, and so the connections, are shared through the cache by the clients with the same TLS configuration
+func newHTTPClient(auth *authConfig, headers map[string]string, timeout time.Duration, cache *Cache) *http.Client {
+	transport := cache.getTransport(auth.tlsConfigKey, func() *http.Transport {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = auth.tlsConfig
+		return transport
+	})
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &authRoundTripper{
+			next:    transport,
+			auth:    auth,
+			headers: headers,
+		},
+	}
+}
+
+type authRoundTripper struct {
+	next    http.RoundTripper
+	auth    *authConfig
+	headers map[string]string
+}
+
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip must not modify the request
+	req = req.Clone(req.Context())
+	for key, value := range rt.headers {
+		req.Header.Set(key, value)
+	}
+
+	switch {
+	case rt.auth.bearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+rt.auth.bearerToken)
+	case rt.auth.username != "" || rt.auth.password != "":
+		req.SetBasicAuth(rt.auth.username, rt.auth.password)
+	}
+
+	return rt.next.RoundTrip(req)
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach the wrapped transport
+func (rt *authRoundTripper) CloseIdleConnections() {
+	if closer, ok := rt.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
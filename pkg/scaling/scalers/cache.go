@@ -0,0 +1,223 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// clientIdleTimeout is how long the clients handed out by the cache are kept without being used,
+// like the idle connections of the default HTTP transport
+const clientIdleTimeout = 90 * time.Second
+
+// Cache is shared by the scalers of all the ElastiServices, so that services pointing at the same
+// server don't repeat the same queries within a polling interval and reuse their connections.
+// A nil Cache is valid and disables caching.
+type Cache struct {
+	// ttl is how long the results fetched through this cache are reused for
+	ttl time.Duration
+	*cacheStore
+}
+
+// cacheStore holds the results, Secrets, transports and clients shared by a Cache and the views created with WithTTL
+type cacheStore struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	results map[string]*cachedValue[float64]
+	// secrets holds the data of the Secrets referenced by the triggers, so they aren't read from the API server
+	// every time a scaler is created
+	secrets    map[string]*cachedValue[map[string][]byte]
+	transports map[string]*cachedTransport
+	// clients holds the connections to the systems without an HTTP API, e.g. kafka clients and database handles
+	clients map[string]*cachedClient
+}
+
+type cachedTransport struct {
+	transport *http.Transport
+	lastUsed  time.Time
+}
+
+type cachedClient struct {
+	// mu is held while connecting, so concurrent lookups of the same key wait for a single client
+	mu       sync.Mutex
+	client   io.Closer
+	lastUsed time.Time
+}
+
+type cachedValue[T any] struct {
+	// mu is held while fetching, so concurrent lookups of the same key wait for a single fetch
+	mu        sync.Mutex
+	value     T
+	err       error
+	fetchedAt time.Time
+	expiresAt time.Time
+}
+
+// NewCache creates a cache keeping results for the ttl, which should be shorter than the polling interval
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl: ttl,
+		cacheStore: &cacheStore{
+			now:        time.Now,
+			results:    make(map[string]*cachedValue[float64]),
+			secrets:    make(map[string]*cachedValue[map[string][]byte]),
+			transports: make(map[string]*cachedTransport),
+			clients:    make(map[string]*cachedClient),
+		},
+	}
+}
+
+// WithTTL returns a view of the cache sharing its results and transports, which reuses results for the ttl.
+// ElastiServices polled more often than the others use a shorter ttl, so they don't act on results older than their polling interval.
+func (c *Cache) WithTTL(ttl time.Duration) *Cache {
+	if c == nil {
+		return nil
+	}
+	return &Cache{ttl: ttl, cacheStore: c.cacheStore}
+}
+
+// getResult returns the cached result for the key, calling fetch if there is none or it has expired.
+// Errors are cached as well, so a server which is down isn't queried once per ElastiService. Context errors aren't,
+// they come from the context of the ElastiService which fetched the result, not from the server.
+func (c *Cache) getResult(key string, fetch func() (float64, error)) (float64, error) {
+	if c == nil {
+		return fetch()
+	}
+	return getCached(c, c.results, key, fetch)
+}
+
+// getSecret returns the cached data of the Secret, calling fetch if there is none or it has expired.
+// Secrets are reused for the ttl like results, so a rotated Secret is picked up within a polling interval.
+func (c *Cache) getSecret(namespace, name string, fetch func() (map[string][]byte, error)) (map[string][]byte, error) {
+	if c == nil {
+		return fetch()
+	}
+	return getCached(c, c.secrets, namespace+"/"+name, fetch)
+}
+
+// getCached returns the value cached for the key, calling fetch if there is none or it has expired for the ttl of the view
+func getCached[T any](c *Cache, values map[string]*cachedValue[T], key string, fetch func() (T, error)) (T, error) {
+	c.mu.Lock()
+	result, ok := values[key]
+	if !ok {
+		result = &cachedValue[T]{}
+		values[key] = result
+	}
+	c.mu.Unlock()
+
+	result.mu.Lock()
+	defer result.mu.Unlock()
+	// The result may have been fetched through a view with another ttl, so it is reused only while it is fresh for both
+	if now := c.now(); now.Before(result.expiresAt) && now.Before(result.fetchedAt.Add(c.ttl)) {
+		return result.value, result.err
+	}
+	value, err := fetch()
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return value, err
+	}
+	result.value, result.err = value, err
+	result.fetchedAt = c.now()
+	result.expiresAt = result.fetchedAt.Add(c.ttl)
+	return result.value, result.err
+}
+
+// getTransport returns the transport shared by the clients with the same TLS configuration, newTransport is called if there is none
+func (c *Cache) getTransport(key string, newTransport func() *http.Transport) *http.Transport {
+	if c == nil {
+		return newTransport()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.transports[key]
+	if !ok {
+		cached = &cachedTransport{transport: newTransport()}
+		c.transports[key] = cached
+	}
+	cached.lastUsed = c.now()
+	return cached.transport
+}
+
+// getClient returns the client shared by the scalers with the same key, newClient is called if there is none.
+// Errors aren't cached, the next lookup creates the client again.
+func (c *Cache) getClient(key string, newClient func() (io.Closer, error)) (io.Closer, error) {
+	if c == nil {
+		return newClient()
+	}
+
+	c.mu.Lock()
+	cached, ok := c.clients[key]
+	if !ok {
+		cached = &cachedClient{}
+		c.clients[key] = cached
+	}
+	c.mu.Unlock()
+
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+	if cached.client == nil {
+		client, err := newClient()
+		if err != nil {
+			return nil, err
+		}
+		cached.client = client
+	}
+	cached.lastUsed = c.now()
+	return cached.client, nil
+}
+
+// shared reports whether the resources handed out by the cache are shared and must not be closed by the scalers
+func (c *Cache) shared() bool {
+	return c != nil
+}
+
+// EvictExpired removes the expired results and Secrets, so the results of deleted ElastiServices don't pile up.
+// It also closes and removes the transports which weren't used for longer than their idle connections are kept,
+// and the clients which weren't used for longer than clientIdleTimeout, e.g. those of deleted or reconfigured triggers.
+func (c *Cache) EvictExpired() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	evictExpired(c.results, now)
+	evictExpired(c.secrets, now)
+	for key, cached := range c.transports {
+		if now.Sub(cached.lastUsed) > max(cached.transport.IdleConnTimeout, c.ttl) {
+			cached.transport.CloseIdleConnections()
+			delete(c.transports, key)
+		}
+	}
+	for key, cached := range c.clients {
+		// Clients being created are locked and kept
+		if !cached.mu.TryLock() {
+			continue
+		}
+		if now.Sub(cached.lastUsed) > max(clientIdleTimeout, c.ttl) {
+			if cached.client != nil {
+				_ = cached.client.Close()
+			}
+			delete(c.clients, key)
+		}
+		cached.mu.Unlock()
+	}
+}
+
+func evictExpired[T any](values map[string]*cachedValue[T], now time.Time) {
+	for key, result := range values {
+		// Results being fetched are locked and kept
+		if !result.mu.TryLock() {
+			continue
+		}
+		if !now.Before(result.expiresAt) {
+			delete(values, key)
+		}
+		result.mu.Unlock()
+	}
+}
@@ -0,0 +1,217 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCacheGetResult(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(10 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	fetches := 0
+	fetch := func(value float64, err error) func() (float64, error) {
+		return func() (float64, error) {
+			fetches++
+			return value, err
+		}
+	}
+
+	value, err := cache.getResult("a", fetch(1, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, value)
+
+	// Served from the cache until the ttl expires
+	value, err = cache.getResult("a", fetch(2, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, value)
+	assert.Equal(t, 1, fetches)
+
+	now = now.Add(10 * time.Second)
+	value, err = cache.getResult("a", fetch(2, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, value)
+	assert.Equal(t, 2, fetches)
+
+	// Errors are cached as well
+	now = now.Add(5 * time.Second)
+	_, err = cache.getResult("b", fetch(-1, errors.New("connection refused")))
+	assert.Error(t, err)
+	_, err = cache.getResult("b", fetch(3, nil))
+	assert.Error(t, err)
+	assert.Equal(t, 3, fetches)
+
+	// Context errors of the ElastiService which fetched the result aren't
+	_, err = cache.getResult("c", fetch(-1, fmt.Errorf("query failed: %w", context.Canceled)))
+	assert.ErrorIs(t, err, context.Canceled)
+	value, err = cache.getResult("c", fetch(4, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, value)
+	assert.Equal(t, 5, fetches)
+
+	now = now.Add(5 * time.Second)
+	cache.EvictExpired()
+	assert.Len(t, cache.results, 2)
+	now = now.Add(10 * time.Second)
+	cache.EvictExpired()
+	assert.Empty(t, cache.results)
+
+	// A nil cache always fetches
+	var nilCache *Cache
+	_, err = nilCache.getResult("a", fetch(1, nil))
+	require.NoError(t, err)
+	_, err = nilCache.getResult("a", fetch(1, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 7, fetches)
+}
+
+func TestCacheWithTTL(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	fast := cache.WithTTL(5 * time.Second)
+
+	fetches := 0
+	fetch := func() (float64, error) {
+		fetches++
+		return float64(fetches), nil
+	}
+
+	// Results fetched through the cache are reused by a view only while they are fresh for the view
+	value, err := cache.getResult("a", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, value)
+	now = now.Add(3 * time.Second)
+	value, err = fast.getResult("a", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, value)
+	now = now.Add(3 * time.Second)
+	value, err = fast.getResult("a", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, value)
+
+	// And results fetched through the view expire with its ttl
+	now = now.Add(10 * time.Second)
+	value, err = cache.getResult("a", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, value)
+
+	var nilCache *Cache
+	assert.Nil(t, nilCache.WithTTL(time.Second))
+}
+
+func TestCacheEvictTransports(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(10 * time.Second)
+	cache.now = func() time.Time { return now }
+	newTransport := func() *http.Transport {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.IdleConnTimeout = time.Minute
+		return transport
+	}
+
+	used := cache.getTransport("used", newTransport)
+	unused := cache.getTransport("unused", newTransport)
+	assert.Same(t, used, cache.getTransport("used", newTransport))
+
+	// Transports still in use are kept, the others once their idle connections would have been closed
+	now = now.Add(45 * time.Second)
+	cache.getTransport("used", newTransport)
+	now = now.Add(45 * time.Second)
+	cache.EvictExpired()
+	assert.Len(t, cache.transports, 1)
+	assert.Same(t, used, cache.getTransport("used", newTransport))
+	assert.NotSame(t, unused, cache.getTransport("unused", newTransport))
+}
+
+func TestResolveAuthConfigCachesSecrets(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewCache(10 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	kClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "shop"},
+		Data:       map[string][]byte{"username": []byte("elasti"), "password": []byte("secret")},
+	})
+	gets := 0
+	kClient.PrependReactor("get", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		gets++
+		return false, nil, nil
+	})
+	metadata := &authMetadata{AuthSecretRef: "auth"}
+
+	// The Secret is read once per ttl, not every time a scaler is created
+	for range 3 {
+		auth, err := resolveAuthConfig(ctx, kClient, "shop", metadata, cache)
+		require.NoError(t, err)
+		assert.Equal(t, "elasti", auth.username)
+	}
+	assert.Equal(t, 1, gets)
+
+	// A missing Secret is cached as well
+	for range 2 {
+		_, err := resolveAuthConfig(ctx, kClient, "shop", &authMetadata{AuthSecretRef: "missing"}, cache)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, gets)
+
+	// A rotated Secret is read again once the ttl expires
+	now = now.Add(10 * time.Second)
+	_, err := resolveAuthConfig(ctx, kClient, "shop", metadata, cache)
+	require.NoError(t, err)
+	assert.Equal(t, 3, gets)
+	cache.EvictExpired()
+	assert.Len(t, cache.secrets, 1)
+
+	// Without a cache the Secret is always read
+	_, err = resolveAuthConfig(ctx, kClient, "shop", metadata, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, gets)
+}
+
+func TestPrometheusScalerSharedCache(t *testing.T) {
+	ctx := context.Background()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [%d, "1"]}]}}`, time.Now().Unix())
+	}))
+	defer server.Close()
+
+	cache := NewCache(time.Minute)
+	newScaler := func(tenant string) Scaler {
+		metadata := json.RawMessage(fmt.Sprintf(`{"serverAddress": "%s", "query": "up", "threshold": "1", "headers": {"X-Scope-OrgID": "%s"}}`, server.URL, tenant))
+		scaler, err := NewPrometheusScaler(ctx, fake.NewSimpleClientset(), "default", metadata, time.Minute, cache)
+		require.NoError(t, err)
+		return scaler
+	}
+
+	for _, tenant := range []string{"tenant-1", "tenant-1", "tenant-1", "tenant-2"} {
+		scaler := newScaler(tenant)
+		scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+		require.NoError(t, err)
+		assert.False(t, scaleToZero)
+		require.NoError(t, scaler.Close(ctx))
+	}
+
+	// One query per tenant, the scalers of the same tenant share the result
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Len(t, cache.transports, 1)
+}
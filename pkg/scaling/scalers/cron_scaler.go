@@ -0,0 +1,102 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+type cronScaler struct {
+	metadata      *cronMetadata
+	location      *time.Location
+	startSchedule cron.Schedule
+	endSchedule   cron.Schedule
+	// now is overridden in tests to evaluate the schedule at a fixed time
+	now func() time.Time
+}
+
+// cronMetadata describes a recurring window during which the target is kept awake.
+// Start and End are standard 5 field cron expressions evaluated in Timezone.
+type cronMetadata struct {
+	Timezone string `json:"timezone"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+}
+
+// cronParser only accepts the 5 fields, descriptors like @daily or @every 5m don't describe a window and are rejected
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+func NewCronScaler(metadata json.RawMessage) (Scaler, error) {
+	parsedMetadata, err := parseCronMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cron scaler: %w", err)
+	}
+
+	location, err := time.LoadLocation(parsedMetadata.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cron scaler: invalid timezone %s: %w", parsedMetadata.Timezone, err)
+	}
+	startSchedule, err := cronParser.Parse(parsedMetadata.Start)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cron scaler: invalid start schedule %s: %w", parsedMetadata.Start, err)
+	}
+	endSchedule, err := cronParser.Parse(parsedMetadata.End)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cron scaler: invalid end schedule %s: %w", parsedMetadata.End, err)
+	}
+
+	return &cronScaler{
+		metadata:      parsedMetadata,
+		location:      location,
+		startSchedule: startSchedule,
+		endSchedule:   endSchedule,
+		now:           time.Now,
+	}, nil
+}
+
+func parseCronMetadata(jsonMetadata json.RawMessage) (*cronMetadata, error) {
+	metadata := &cronMetadata{}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata.Timezone == "" {
+		return nil, fmt.Errorf("timezone is required")
+	}
+	if metadata.Start == "" || metadata.End == "" {
+		return nil, fmt.Errorf("both start and end schedules are required")
+	}
+	if metadata.Start == metadata.End {
+		return nil, fmt.Errorf("start and end schedules must be different")
+	}
+	return metadata, nil
+}
+
+// isActive reports whether the current time is within a start/end window.
+// Inside a window the next event to fire is the end, outside of it the next event is the start.
+func (s *cronScaler) isActive() bool {
+	now := s.now().In(s.location)
+	nextStart := s.startSchedule.Next(now)
+	nextEnd := s.endSchedule.Next(now)
+	return nextEnd.Before(nextStart)
+}
+
+func (s *cronScaler) ShouldScaleToZero(_ context.Context) (bool, error) {
+	return !s.isActive(), nil
+}
+
+func (s *cronScaler) ShouldScaleFromZero(_ context.Context) (bool, error) {
+	return s.isActive(), nil
+}
+
+// IsHealthy always returns true as the cron scaler doesn't depend on any external system
+func (s *cronScaler) IsHealthy(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s *cronScaler) Close(_ context.Context) error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronScaler(t *testing.T) {
+	metadata := json.RawMessage(`{"timezone": "Asia/Kolkata", "start": "0 9 * * 1-5", "end": "0 18 * * 1-5"}`)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		now               time.Time
+		expectScaleToZero bool
+	}{
+		{
+			name:              "Within business hours",
+			now:               time.Date(2024, time.March, 6, 11, 0, 0, 0, kolkata),
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Within business hours, evaluated in UTC",
+			now:               time.Date(2024, time.March, 6, 5, 0, 0, 0, time.UTC),
+			expectScaleToZero: false,
+		},
+		{
+			name:              "After business hours",
+			now:               time.Date(2024, time.March, 6, 19, 0, 0, 0, kolkata),
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Before business hours, evaluated in UTC",
+			now:               time.Date(2024, time.March, 6, 2, 0, 0, 0, time.UTC),
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Weekend",
+			now:               time.Date(2024, time.March, 9, 11, 0, 0, 0, kolkata),
+			expectScaleToZero: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaler, err := NewCronScaler(metadata)
+			require.NoError(t, err)
+			scaler.(*cronScaler).now = func() time.Time { return tt.now }
+
+			scaleToZero, err := scaler.ShouldScaleToZero(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, !tt.expectScaleToZero, scaleFromZero)
+		})
+	}
+}
+
+func TestNewCronScalerInvalidMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+	}{
+		{name: "Missing timezone", metadata: `{"start": "0 9 * * *", "end": "0 18 * * *"}`},
+		{name: "Unknown timezone", metadata: `{"timezone": "Mars/Olympus", "start": "0 9 * * *", "end": "0 18 * * *"}`},
+		{name: "Invalid start", metadata: `{"timezone": "UTC", "start": "every morning", "end": "0 18 * * *"}`},
+		{name: "Every descriptor", metadata: `{"timezone": "UTC", "start": "@every 5m", "end": "0 18 * * *"}`},
+		{name: "Daily descriptor", metadata: `{"timezone": "UTC", "start": "0 9 * * *", "end": "@daily"}`},
+		{name: "Same start and end", metadata: `{"timezone": "UTC", "start": "0 9 * * *", "end": "0 9 * * *"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCronScaler(json.RawMessage(tt.metadata))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package scalers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/truefoundry/elasti/pkg/scaling/scalers/externalscaler"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	externalScalerTimeout = 5 * time.Second
+)
+
+// externalAuthMetadataKeys are the keys of the metadata used by the operator to authenticate with the external scaler,
+// they aren't forwarded to it
+var externalAuthMetadataKeys = []string{"authSecretRef", "tlsSecretRef", "enableTLS"}
+
+type externalScaler struct {
+	metadata        *externalMetadata
+	conn            *grpc.ClientConn
+	client          externalscaler.ExternalScalerClient
+	scaledObjectRef *externalscaler.ScaledObjectRef
+}
+
+type externalMetadata struct {
+	// ScalerAddress is the address of a gRPC server implementing the KEDA external scaler contract
+	ScalerAddress string `json:"scalerAddress"`
+	// MetricName is optional, when set GetMetrics is called if IsActive reports the service as inactive
+	MetricName string `json:"metricName"`
+	// thresholdMetadata is compared with the sum of the metric values, the threshold is required with the MetricName
+	thresholdMetadata
+	authMetadata
+}
+
+// NewExternalScaler creates a scaler for the external scaler running at the scalerAddress
+// The trigger metadata is forwarded to the external scaler as scalerMetadata, like KEDA does, except for the authentication.
+// The credentials of the authSecretRef are sent with every call, over TLS only.
+func NewExternalScaler(ctx context.Context, kClient kubernetes.Interface, namespace, name string, metadata json.RawMessage, cache *Cache) (Scaler, error) {
+	parsedMetadata, err := parseExternalMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating external scaler: %w", err)
+	}
+	scalerMetadata, err := parseScalerMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating external scaler: %w", err)
+	}
+
+	auth, err := resolveAuthConfig(ctx, kClient, namespace, &parsedMetadata.authMetadata, cache)
+	if err != nil {
+		return nil, fmt.Errorf("error creating external scaler: %w", err)
+	}
+	transportCredentials := insecure.NewCredentials()
+	if auth.tlsConfig != nil {
+		transportCredentials = credentials.NewTLS(auth.tlsConfig)
+	}
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}
+	if parsedMetadata.AuthSecretRef != "" {
+		if auth.bearerToken == "" && auth.username == "" && auth.password == "" {
+			return nil, fmt.Errorf("error creating external scaler: secret %s/%s has no %s, or %s and %s",
+				namespace, parsedMetadata.AuthSecretRef, secretKeyBearerToken, secretKeyUsername, secretKeyPassword)
+		}
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(&grpcAuthCredentials{auth: auth}))
+	}
+
+	// grpc.NewClient doesn't connect to the server, connectivity is checked in IsHealthy
+	conn, err := grpc.NewClient(parsedMetadata.ScalerAddress, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating external scaler: %w", err)
+	}
+
+	return &externalScaler{
+		metadata: parsedMetadata,
+		conn:     conn,
+		client:   externalscaler.NewExternalScalerClient(conn),
+		scaledObjectRef: &externalscaler.ScaledObjectRef{
+			Name:           name,
+			Namespace:      namespace,
+			ScalerMetadata: scalerMetadata,
+		},
+	}, nil
+}
+
+func parseExternalMetadata(jsonMetadata json.RawMessage) (*externalMetadata, error) {
+	metadata := &externalMetadata{}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata.ScalerAddress == "" {
+		return nil, fmt.Errorf("scalerAddress is required")
+	}
+	if metadata.MetricName != "" {
+		if err := metadata.requireThreshold(); err != nil {
+			return nil, fmt.Errorf("%w with metricName", err)
+		}
+	}
+	if metadata.AuthSecretRef != "" && metadata.TLSSecretRef == "" && !metadata.EnableTLS {
+		return nil, fmt.Errorf("authSecretRef requires tlsSecretRef or enableTLS, credentials aren't sent in plaintext")
+	}
+	return metadata, nil
+}
+
+// parseScalerMetadata converts the trigger metadata to the string map expected by external scalers
+// Non string values are passed as their JSON representation, the authentication keys are left out
+func parseScalerMetadata(jsonMetadata json.RawMessage) (map[string]string, error) {
+	rawMetadata := map[string]json.RawMessage{}
+	if err := json.Unmarshal(jsonMetadata, &rawMetadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	for _, key := range externalAuthMetadataKeys {
+		delete(rawMetadata, key)
+	}
+
+	scalerMetadata := make(map[string]string, len(rawMetadata))
+	for key, value := range rawMetadata {
+		var stringValue string
+		if err := json.Unmarshal(value, &stringValue); err != nil {
+			stringValue = string(value)
+		}
+		scalerMetadata[key] = stringValue
+	}
+	return scalerMetadata, nil
+}
+
+// grpcAuthCredentials sends the credentials of the authSecretRef with every call, as a bearer token,
+// or with basic auth when the Secret has no bearer token
+type grpcAuthCredentials struct {
+	auth *authConfig
+}
+
+func (c *grpcAuthCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	if c.auth.bearerToken != "" {
+		return map[string]string{"authorization": "Bearer " + c.auth.bearerToken}, nil
+	}
+	basicAuth := base64.StdEncoding.EncodeToString([]byte(c.auth.username + ":" + c.auth.password))
+	return map[string]string{"authorization": "Basic " + basicAuth}, nil
+}
+
+// RequireTransportSecurity keeps the credentials from being sent over a connection without TLS
+func (c *grpcAuthCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// isActive reports whether IsActive returns true, or else whether the sum of the metric values is active
+// according to the comparison, when a metricName is set
+func (s *externalScaler) isActive(ctx context.Context, metricActive func(value float64) bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, externalScalerTimeout)
+	defer cancel()
+
+	isActiveResponse, err := s.client.IsActive(ctx, s.scaledObjectRef)
+	if err != nil {
+		return false, fmt.Errorf("failed to call IsActive on external scaler %s: %w", s.metadata.ScalerAddress, err)
+	}
+	if isActiveResponse.GetResult() || s.metadata.MetricName == "" {
+		return isActiveResponse.GetResult(), nil
+	}
+
+	metricsResponse, err := s.client.GetMetrics(ctx, &externalscaler.GetMetricsRequest{
+		ScaledObjectRef: s.scaledObjectRef,
+		MetricName:      s.metadata.MetricName,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to call GetMetrics on external scaler %s: %w", s.metadata.ScalerAddress, err)
+	}
+
+	var value float64
+	for _, metricValue := range metricsResponse.GetMetricValues() {
+		if metricValue.GetMetricValueFloat() != 0 {
+			value += metricValue.GetMetricValueFloat()
+		} else {
+			value += float64(metricValue.GetMetricValue())
+		}
+	}
+	return metricActive(value), nil
+}
+
+func (s *externalScaler) ShouldScaleToZero(ctx context.Context) (bool, error) {
+	active, err := s.isActive(ctx, func(value float64) bool {
+		return !s.metadata.shouldScaleToZero(value)
+	})
+	if err != nil {
+		return false, err
+	}
+	return !active, nil
+}
+
+func (s *externalScaler) ShouldScaleFromZero(ctx context.Context) (bool, error) {
+	active, err := s.isActive(ctx, s.metadata.shouldScaleFromZero)
+	if err != nil {
+		return true, err
+	}
+	return active, nil
+}
+
+func (s *externalScaler) IsHealthy(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, externalScalerTimeout)
+	defer cancel()
+
+	s.conn.Connect()
+	for {
+		state := s.conn.GetState()
+		if state == connectivity.Ready {
+			return true, nil
+		}
+		if !s.conn.WaitForStateChange(ctx, state) {
+			return false, fmt.Errorf("failed to connect to external scaler %s, connection state: %s", s.metadata.ScalerAddress, state)
+		}
+	}
+}
+
+func (s *externalScaler) Close(_ context.Context) error {
+	if err := s.conn.Close(); err != nil {
+		return fmt.Errorf("failed to close external scaler connection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package scalers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/truefoundry/elasti/pkg/scaling/scalers/externalscaler"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpcmetadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testExternalScalerServer reports the service as active based on the "active" scalerMetadata
+// and returns the "value" scalerMetadata as the metric value. It rejects the calls forwarding the authentication keys.
+type testExternalScalerServer struct {
+	externalscaler.UnimplementedExternalScalerServer
+}
+
+func (s *testExternalScalerServer) IsActive(_ context.Context, ref *externalscaler.ScaledObjectRef) (*externalscaler.IsActiveResponse, error) {
+	if ref.GetName() != "orders" || ref.GetNamespace() != "shop" {
+		return nil, status.Errorf(codes.InvalidArgument, "unexpected scaled object %s/%s", ref.GetNamespace(), ref.GetName())
+	}
+	for _, key := range externalAuthMetadataKeys {
+		if _, ok := ref.GetScalerMetadata()[key]; ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unexpected scalerMetadata %s", key)
+		}
+	}
+	return &externalscaler.IsActiveResponse{Result: ref.GetScalerMetadata()["active"] == "true"}, nil
+}
+
+func (s *testExternalScalerServer) GetMetrics(_ context.Context, req *externalscaler.GetMetricsRequest) (*externalscaler.GetMetricsResponse, error) {
+	var value float64
+	if _, err := fmt.Sscanf(req.GetScaledObjectRef().GetScalerMetadata()["value"], "%g", &value); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid value: %v", err)
+	}
+	return &externalscaler.GetMetricsResponse{
+		MetricValues: []*externalscaler.MetricValue{{MetricName: req.GetMetricName(), MetricValueFloat: value}},
+	}, nil
+}
+
+func startTestExternalScalerServer(t *testing.T, opts ...grpc.ServerOption) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(opts...)
+	externalscaler.RegisterExternalScalerServer(server, &testExternalScalerServer{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestExternalScaler(t *testing.T) {
+	ctx := context.Background()
+	address := startTestExternalScalerServer(t)
+
+	tests := []struct {
+		name              string
+		metadata          string
+		expectScaleToZero bool
+		expectError       bool
+	}{
+		{
+			name:              "Inactive",
+			metadata:          `"active": "false"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Active",
+			metadata:          `"active": "true"`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Inactive with metric above threshold",
+			metadata:          `"active": "false", "metricName": "pending", "value": 5, "threshold": "2"`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Inactive with metric at threshold",
+			metadata:          `"active": "false", "metricName": "pending", "value": 2, "threshold": "2"`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Inactive with metric below threshold",
+			metadata:          `"active": "false", "metricName": "pending", "value": "1.5", "threshold": "2"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Inactive with zero metric",
+			metadata:          `"active": "false", "metricName": "pending", "value": 0, "threshold": "1"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:        "Error from external scaler",
+			metadata:    `"active": "false", "metricName": "pending", "value": "invalid", "threshold": "1"`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := json.RawMessage(fmt.Sprintf(`{"scalerAddress": "%s", %s}`, address, tt.metadata))
+			scaler, err := NewExternalScaler(ctx, fake.NewSimpleClientset(), "shop", "orders", metadata, nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			healthy, err := scaler.IsHealthy(ctx)
+			require.NoError(t, err)
+			assert.True(t, healthy)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			scaleFromZero, fromZeroErr := scaler.ShouldScaleFromZero(ctx)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, scaleToZero)
+				assert.Error(t, fromZeroErr)
+				assert.True(t, scaleFromZero)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, fromZeroErr)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+			assert.Equal(t, !tt.expectScaleToZero, scaleFromZero)
+		})
+	}
+}
+
+func TestExternalScalerAuthentication(t *testing.T) {
+	ctx := context.Background()
+
+	// The certificate of an httptest server is valid for 127.0.0.1
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certServer.Close()
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certServer.Certificate().Raw})
+	address := startTestExternalScalerServer(t,
+		grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: certServer.TLS.Certificates})),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := grpcmetadata.FromIncomingContext(ctx)
+			if authorization := md.Get("authorization"); len(authorization) != 1 ||
+				(authorization[0] != "Bearer scaler-token" && authorization[0] != "Basic ZWxhc3RpOnNlY3JldA==") {
+				return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+			}
+			return handler(ctx, req)
+		}))
+
+	tests := []struct {
+		name        string
+		secretData  map[string][]byte
+		expectError bool
+	}{
+		{
+			name:       "Bearer token",
+			secretData: map[string][]byte{secretKeyBearerToken: []byte("scaler-token")},
+		},
+		{
+			name:       "Basic auth",
+			secretData: map[string][]byte{secretKeyUsername: []byte("elasti"), secretKeyPassword: []byte("secret")},
+		},
+		{
+			name:        "Invalid token",
+			secretData:  map[string][]byte{secretKeyBearerToken: []byte("other-token")},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kClient := fake.NewSimpleClientset(
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "scaler-auth", Namespace: "shop"}, Data: tt.secretData},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "scaler-tls", Namespace: "shop"}, Data: map[string][]byte{secretKeyCA: caData}},
+			)
+			metadata := json.RawMessage(fmt.Sprintf(`{"scalerAddress": "%s", "active": "true", "authSecretRef": "scaler-auth", "tlsSecretRef": "scaler-tls"}`, address))
+			scaler, err := NewExternalScaler(ctx, kClient, "shop", "orders", metadata, nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(ctx)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, scaleFromZero)
+		})
+	}
+}
+
+func TestExternalScalerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	scaler, err := NewExternalScaler(ctx, fake.NewSimpleClientset(), "shop", "orders", json.RawMessage(fmt.Sprintf(`{"scalerAddress": "%s"}`, address)), nil)
+	require.NoError(t, err)
+	defer scaler.Close(ctx)
+
+	healthy, err := scaler.IsHealthy(ctx)
+	assert.Error(t, err)
+	assert.False(t, healthy)
+}
+
+func TestNewExternalScalerInvalidMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+	}{
+		{name: "Missing scalerAddress", metadata: `{"metricName": "pending"}`},
+		{name: "Invalid threshold", metadata: `{"scalerAddress": "localhost:6000", "threshold": "high"}`},
+		{name: "Missing threshold with metricName", metadata: `{"scalerAddress": "localhost:6000", "metricName": "pending"}`},
+		{name: "Missing TLS secret", metadata: `{"scalerAddress": "localhost:6000", "tlsSecretRef": "missing"}`},
+		{name: "Credentials without TLS", metadata: `{"scalerAddress": "localhost:6000", "authSecretRef": "scaler-auth"}`},
+		{name: "Missing auth secret", metadata: `{"scalerAddress": "localhost:6000", "authSecretRef": "missing", "enableTLS": "true"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewExternalScaler(context.Background(), fake.NewSimpleClientset(), "shop", "orders", json.RawMessage(tt.metadata), nil)
+			assert.Error(t, err)
+		})
+	}
+}
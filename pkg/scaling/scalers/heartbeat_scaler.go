@@ -0,0 +1,98 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultHeartbeatStartupGracePeriod = 5 * time.Minute
+)
+
+type heartbeatScaler struct {
+	metadata  *heartbeatMetadata
+	store     *LeaseStore
+	namespace string
+	name      string
+}
+
+type heartbeatMetadata struct {
+	// StartupGracePeriodSeconds is how long after the operator starts the trigger waits for the workloads
+	// to post their leases again, before it lets the service scale to zero. 300 seconds by default
+	StartupGracePeriodSeconds int `json:"startupGracePeriodSeconds,string"`
+}
+
+// NewHeartbeatScaler creates a scaler keeping the ElastiService awake while any of the leases posted for it is live
+func NewHeartbeatScaler(store *LeaseStore, namespace, name string, metadata json.RawMessage) (Scaler, error) {
+	parsedMetadata, err := parseHeartbeatMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating heartbeat scaler: %w", err)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("error creating heartbeat scaler: the lease endpoint isn't running")
+	}
+
+	return &heartbeatScaler{
+		metadata:  parsedMetadata,
+		store:     store,
+		namespace: namespace,
+		name:      name,
+	}, nil
+}
+
+func parseHeartbeatMetadata(jsonMetadata json.RawMessage) (*heartbeatMetadata, error) {
+	metadata := &heartbeatMetadata{}
+	// The trigger has no required metadata
+	if len(jsonMetadata) == 0 {
+		return metadata, nil
+	}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata.StartupGracePeriodSeconds < 0 {
+		return nil, fmt.Errorf("startupGracePeriodSeconds must be a positive number")
+	}
+	return metadata, nil
+}
+
+func (s *heartbeatScaler) startupGracePeriod() time.Duration {
+	if s.metadata.StartupGracePeriodSeconds == 0 {
+		return defaultHeartbeatStartupGracePeriod
+	}
+	return time.Duration(s.metadata.StartupGracePeriodSeconds) * time.Second
+}
+
+// inStartupGracePeriod reports whether the leases lost when the operator restarted may not have been posted again yet
+func (s *heartbeatScaler) inStartupGracePeriod() bool {
+	return s.store.now().Sub(s.store.startedAt) < s.startupGracePeriod()
+}
+
+func (s *heartbeatScaler) ShouldScaleToZero(_ context.Context) (bool, error) {
+	if s.store.liveLeases(s.namespace, s.name) > 0 {
+		return false, nil
+	}
+	if s.inStartupGracePeriod() {
+		return false, fmt.Errorf("waiting %s after the operator started for the leases to be posted again", s.startupGracePeriod())
+	}
+	return true, nil
+}
+
+func (s *heartbeatScaler) ShouldScaleFromZero(_ context.Context) (bool, error) {
+	return s.store.liveLeases(s.namespace, s.name) > 0, nil
+}
+
+func (s *heartbeatScaler) Close(_ context.Context) error {
+	return nil
+}
+
+// IsHealthy reports false during the startup grace period, unless a lease was already posted,
+// so the service isn't scaled to zero because of the leases lost when the operator restarted
+func (s *heartbeatScaler) IsHealthy(_ context.Context) (bool, error) {
+	if s.store.liveLeases(s.namespace, s.name) == 0 && s.inStartupGracePeriod() {
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,150 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaseStore(t *testing.T) {
+	now := time.Now()
+	store := NewLeaseStore(DefaultMaxLeaseDuration)
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Acquire("shop", "checkout", "session-1", now.Add(time.Minute)))
+	require.NoError(t, store.Acquire("shop", "checkout", "session-2", now.Add(10*time.Minute)))
+	require.NoError(t, store.Acquire("shop", "orders", "export-1", now.Add(time.Minute)))
+	assert.Equal(t, 2, store.liveLeases("shop", "checkout"))
+	assert.Equal(t, 1, store.liveLeases("shop", "orders"))
+	assert.Equal(t, 0, store.liveLeases("other", "checkout"))
+
+	// A lease from the same holder replaces the previous one
+	require.NoError(t, store.Acquire("shop", "checkout", "session-1", now.Add(20*time.Minute)))
+	assert.Equal(t, 2, store.liveLeases("shop", "checkout"))
+
+	// A lease expiring in the past releases it
+	require.NoError(t, store.Acquire("shop", "orders", "export-1", now))
+	assert.Equal(t, 0, store.liveLeases("shop", "orders"))
+
+	assert.Error(t, store.Acquire("shop", "checkout", "session-3", now.Add(DefaultMaxLeaseDuration+time.Minute)))
+
+	now = now.Add(15 * time.Minute)
+	assert.Equal(t, 1, store.liveLeases("shop", "checkout"))
+
+	now = now.Add(time.Hour)
+	store.EvictExpired()
+	assert.Empty(t, store.leases)
+}
+
+func TestLeaseStoreLimits(t *testing.T) {
+	now := time.Now()
+	store := NewLeaseStore(10 * time.Minute)
+	store.now = func() time.Time { return now }
+
+	// The maximum duration of a lease is configurable
+	require.NoError(t, store.Acquire("shop", "checkout", "session-0", now.Add(10*time.Minute)))
+	assert.Error(t, store.Acquire("shop", "checkout", "session-0", now.Add(11*time.Minute)))
+
+	for i := 1; i < MaxLeasesPerService; i++ {
+		require.NoError(t, store.Acquire("shop", "checkout", fmt.Sprintf("session-%d", i), now.Add(time.Minute)))
+	}
+	assert.ErrorIs(t, store.Acquire("shop", "checkout", "session-new", now.Add(time.Minute)), ErrTooManyLeases)
+	// The leases already held can still be renewed, and other services aren't limited
+	require.NoError(t, store.Acquire("shop", "checkout", "session-1", now.Add(5*time.Minute)))
+	require.NoError(t, store.Acquire("shop", "orders", "session-new", now.Add(time.Minute)))
+
+	// The expired leases make room for new ones
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, store.Acquire("shop", "checkout", "session-new", now.Add(time.Minute)))
+	assert.Equal(t, 3, store.liveLeases("shop", "checkout"))
+}
+
+func TestHeartbeatScaler(t *testing.T) {
+	ctx := context.Background()
+	startedAt := time.Now()
+
+	tests := []struct {
+		name                string
+		metadata            string
+		sinceStart          time.Duration
+		leaseFor            time.Duration
+		expectHealthy       bool
+		expectScaleToZero   bool
+		expectScaleFromZero bool
+	}{
+		{
+			name:                "Live lease",
+			sinceStart:          time.Hour,
+			leaseFor:            time.Minute,
+			expectHealthy:       true,
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "No lease",
+			sinceStart:          time.Hour,
+			expectHealthy:       true,
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "Live lease in the startup grace period",
+			sinceStart:          time.Minute,
+			leaseFor:            time.Minute,
+			expectHealthy:       true,
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:          "No lease in the startup grace period",
+			sinceStart:    time.Minute,
+			expectHealthy: false,
+		},
+		{
+			name:                "No lease after a custom startup grace period",
+			metadata:            `{"startupGracePeriodSeconds": "30"}`,
+			sinceStart:          time.Minute,
+			expectHealthy:       true,
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := startedAt.Add(tt.sinceStart)
+			store := NewLeaseStore(DefaultMaxLeaseDuration)
+			store.startedAt = startedAt
+			store.now = func() time.Time { return now }
+			if tt.leaseFor > 0 {
+				require.NoError(t, store.Acquire("shop", "checkout", "session-1", now.Add(tt.leaseFor)))
+			}
+			// Leases of other ElastiServices are ignored
+			require.NoError(t, store.Acquire("shop", "orders", "session-2", now.Add(time.Minute)))
+
+			scaler, err := NewHeartbeatScaler(store, "shop", "checkout", json.RawMessage(tt.metadata))
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			healthy, err := scaler.IsHealthy(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectHealthy, healthy)
+			if !tt.expectHealthy {
+				return
+			}
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleFromZero, scaleFromZero)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package scalers
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxLeaseDuration bounds how long a single lease can keep a service awake, so a lease which is never
+	// released can't keep it awake for long
+	DefaultMaxLeaseDuration = time.Hour
+	// MaxLeasesPerService bounds the number of live leases, i.e. sessions, kept for an ElastiService
+	MaxLeasesPerService = 100
+)
+
+// ErrTooManyLeases is returned when a new lease is posted for an ElastiService which already has MaxLeasesPerService live leases
+var ErrTooManyLeases = errors.New("too many leases")
+
+// LeaseStore keeps the leases posted by the workloads to keep their ElastiService awake,
+// the heartbeat triggers read the live leases from it
+type LeaseStore struct {
+	now func() time.Time
+	// startedAt is when the store was created, the leases posted before are lost on operator restarts
+	startedAt time.Time
+	// maxLeaseDuration is how far in the future a lease can expire
+	maxLeaseDuration time.Duration
+
+	mu sync.RWMutex
+	// leases maps the namespace/name of the ElastiService to the expiry of the leases by holder
+	leases map[string]map[string]time.Time
+}
+
+// NewLeaseStore creates a LeaseStore accepting leases up to maxLeaseDuration long
+func NewLeaseStore(maxLeaseDuration time.Duration) *LeaseStore {
+	return &LeaseStore{
+		now:              time.Now,
+		startedAt:        time.Now(),
+		maxLeaseDuration: maxLeaseDuration,
+		leases:           make(map[string]map[string]time.Time),
+	}
+}
+
+// Acquire stores the lease of the holder on the ElastiService until the given time, replacing the previous one.
+// A time in the past releases the lease.
+func (l *LeaseStore) Acquire(namespace, name, holder string, until time.Time) error {
+	now := l.now()
+	if until.Sub(now) > l.maxLeaseDuration {
+		return fmt.Errorf("lease expires after %s, the maximum duration of a lease", l.maxLeaseDuration)
+	}
+	key := namespace + "/" + name
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !until.After(now) {
+		delete(l.leases[key], holder)
+		if len(l.leases[key]) == 0 {
+			delete(l.leases, key)
+		}
+		return nil
+	}
+	leases, ok := l.leases[key]
+	if !ok {
+		leases = make(map[string]time.Time)
+		l.leases[key] = leases
+	}
+	if _, renewed := leases[holder]; !renewed && len(leases) >= MaxLeasesPerService {
+		for h, u := range leases {
+			if !u.After(now) {
+				delete(leases, h)
+			}
+		}
+		if len(leases) >= MaxLeasesPerService {
+			return fmt.Errorf("%w: %s already has %d live leases", ErrTooManyLeases, key, MaxLeasesPerService)
+		}
+	}
+	leases[holder] = until
+	return nil
+}
+
+// liveLeases returns the number of leases on the ElastiService which haven't expired
+func (l *LeaseStore) liveLeases(namespace, name string) int {
+	now := l.now()
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var live int
+	for _, until := range l.leases[namespace+"/"+name] {
+		if until.After(now) {
+			live++
+		}
+	}
+	return live
+}
+
+// EvictExpired removes the expired leases
+func (l *LeaseStore) EvictExpired() {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, leases := range l.leases {
+		for holder, until := range leases {
+			if !until.After(now) {
+				delete(leases, holder)
+			}
+		}
+		if len(leases) == 0 {
+			delete(l.leases, key)
+		}
+	}
+}
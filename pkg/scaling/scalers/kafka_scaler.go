@@ -0,0 +1,299 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	kafkaClientTimeout = 5 * time.Second
+	// kafkaCallTimeout bounds a whole check, which makes several requests to the brokers
+	kafkaCallTimeout = 3 * kafkaClientTimeout
+	// invalidOffset is returned by kafka for partitions where the consumer group hasn't committed an offset yet
+	invalidOffset = -1
+)
+
+// kafkaSASLMechanisms maps the sasl metadata to the SASL mechanisms of sarama
+var kafkaSASLMechanisms = map[string]sarama.SASLMechanism{
+	"plaintext":    sarama.SASLTypePlaintext,
+	"scram_sha256": sarama.SASLTypeSCRAMSHA256,
+	"scram_sha512": sarama.SASLTypeSCRAMSHA512,
+}
+
+type kafkaScaler struct {
+	metadata *kafkaMetadata
+	config   *sarama.Config
+	// cache shares the kafka clients between the scalers using the same brokers and credentials
+	cache    *Cache
+	cacheKey string
+
+	// mu guards the client, which is set by calls still running in the background after their context is done
+	mu     sync.Mutex
+	client *kafkaClient
+	closed bool
+}
+
+// kafkaClient is the connection to a kafka cluster, shared through the cache
+type kafkaClient struct {
+	client sarama.Client
+	admin  sarama.ClusterAdmin
+}
+
+// Close closes the admin, which also closes the underlying client
+func (c *kafkaClient) Close() error {
+	return c.admin.Close()
+}
+
+type kafkaMetadata struct {
+	BootstrapServers string `json:"bootstrapServers"`
+	ConsumerGroup    string `json:"consumerGroup"`
+	// Topic is optional, when empty the lag is calculated for all topics the consumer group has committed offsets for
+	Topic string `json:"topic"`
+	// thresholdMetadata is compared with the total lag, the threshold is 1 by default so the consumer group is idle without lag
+	thresholdMetadata
+	// ScaleToZeroOnInvalidOffset treats partitions without a committed offset as having no lag
+	ScaleToZeroOnInvalidOffset bool `json:"scaleToZeroOnInvalidOffset,string"`
+	// SASL is the SASL mechanism authenticating with the username and password of the authSecretRef,
+	// one of plaintext, scram_sha256 or scram_sha512
+	SASL string `json:"sasl"`
+	authMetadata
+}
+
+func NewKafkaScaler(ctx context.Context, kClient kubernetes.Interface, namespace string, metadata json.RawMessage, cache *Cache) (Scaler, error) {
+	parsedMetadata, err := parseKafkaMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kafka scaler: %w", err)
+	}
+
+	auth, err := resolveAuthConfig(ctx, kClient, namespace, &parsedMetadata.authMetadata, cache)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kafka scaler: %w", err)
+	}
+
+	config := sarama.NewConfig()
+	config.ClientID = "elasti"
+	config.Net.DialTimeout = kafkaClientTimeout
+	config.Net.ReadTimeout = kafkaClientTimeout
+	config.Net.WriteTimeout = kafkaClientTimeout
+	config.Metadata.Retry.Max = 1
+	if auth.tlsConfig != nil {
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = auth.tlsConfig
+	}
+	if parsedMetadata.SASL != "" {
+		mechanism := kafkaSASLMechanisms[parsedMetadata.SASL]
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Mechanism = mechanism
+		config.Net.SASL.User = auth.username
+		config.Net.SASL.Password = auth.password
+		if mechanism != sarama.SASLTypePlaintext {
+			config.Net.SASL.SCRAMClientGeneratorFunc = newSCRAMClientGenerator(mechanism)
+		}
+	}
+
+	return &kafkaScaler{
+		metadata: parsedMetadata,
+		config:   config,
+		cache:    cache,
+		cacheKey: hashValues(parsedMetadata.BootstrapServers, parsedMetadata.SASL, auth.cacheKey()),
+	}, nil
+}
+
+func parseKafkaMetadata(jsonMetadata json.RawMessage) (*kafkaMetadata, error) {
+	metadata := &kafkaMetadata{}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata.BootstrapServers == "" {
+		return nil, fmt.Errorf("bootstrapServers is required")
+	}
+	if metadata.ConsumerGroup == "" {
+		return nil, fmt.Errorf("consumerGroup is required")
+	}
+	metadata.defaultThreshold(1)
+	if metadata.SASL != "" {
+		if _, ok := kafkaSASLMechanisms[metadata.SASL]; !ok {
+			return nil, fmt.Errorf("unsupported sasl: %s, expected one of plaintext, scram_sha256 or scram_sha512", metadata.SASL)
+		}
+		if metadata.AuthSecretRef == "" {
+			return nil, fmt.Errorf("authSecretRef is required with sasl")
+		}
+	} else if metadata.AuthSecretRef != "" {
+		return nil, fmt.Errorf("sasl is required with authSecretRef")
+	}
+	return metadata, nil
+}
+
+// connect lazily gets the kafka client from the cache, so an unreachable cluster is reported by IsHealthy
+func (s *kafkaScaler) connect() (*kafkaClient, error) {
+	s.mu.Lock()
+	connected := s.client
+	s.mu.Unlock()
+	if connected != nil {
+		return connected, nil
+	}
+
+	client, err := s.cache.getClient("kafka/"+s.cacheKey, func() (io.Closer, error) {
+		brokers := strings.Split(s.metadata.BootstrapServers, ",")
+		for i := range brokers {
+			brokers[i] = strings.TrimSpace(brokers[i])
+		}
+
+		client, err := sarama.NewClient(brokers, s.config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kafka client: %w", err)
+		}
+		admin, err := sarama.NewClusterAdminFromClient(client)
+		if err != nil {
+			_ = client.Close()
+			return nil, fmt.Errorf("failed to create kafka cluster admin: %w", err)
+		}
+		return &kafkaClient{client: client, admin: admin}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		// The scaler was closed while connecting in the background
+		if !s.cache.shared() {
+			_ = client.Close()
+		}
+		return nil, errors.New("kafka scaler is closed")
+	}
+	if s.client == nil {
+		s.client = client.(*kafkaClient)
+	} else if !s.cache.shared() {
+		_ = client.Close()
+	}
+	return s.client, nil
+}
+
+// withContext runs the call of the kafka client, which doesn't take a context, until the context is done
+// or kafkaCallTimeout passes. The call keeps running in the background until the client times out,
+// but the evaluation doesn't wait for it.
+func withContext[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, kafkaCallTimeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := call()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+func (s *kafkaScaler) getTotalLag() (int64, error) {
+	kafka, err := s.connect()
+	if err != nil {
+		return -1, err
+	}
+
+	// A nil map makes kafka return the committed offsets for all the topics of the consumer group
+	var topicPartitions map[string][]int32
+	if s.metadata.Topic != "" {
+		partitions, err := kafka.client.Partitions(s.metadata.Topic)
+		if err != nil {
+			return -1, fmt.Errorf("failed to get partitions for topic %s: %w", s.metadata.Topic, err)
+		}
+		topicPartitions = map[string][]int32{s.metadata.Topic: partitions}
+	}
+
+	offsets, err := kafka.admin.ListConsumerGroupOffsets(s.metadata.ConsumerGroup, topicPartitions)
+	if err != nil {
+		return -1, fmt.Errorf("failed to list offsets for consumer group %s: %w", s.metadata.ConsumerGroup, err)
+	}
+	if !errors.Is(offsets.Err, sarama.ErrNoError) {
+		return -1, fmt.Errorf("failed to list offsets for consumer group %s: %w", s.metadata.ConsumerGroup, offsets.Err)
+	}
+
+	var totalLag int64
+	for topic, partitions := range offsets.Blocks {
+		for partition, block := range partitions {
+			if !errors.Is(block.Err, sarama.ErrNoError) {
+				return -1, fmt.Errorf("failed to get committed offset for %s/%d: %w", topic, partition, block.Err)
+			}
+
+			if block.Offset == invalidOffset {
+				if !s.metadata.ScaleToZeroOnInvalidOffset {
+					totalLag++
+				}
+				continue
+			}
+
+			latestOffset, err := kafka.client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return -1, fmt.Errorf("failed to get latest offset for %s/%d: %w", topic, partition, err)
+			}
+			if lag := latestOffset - block.Offset; lag > 0 {
+				totalLag += lag
+			}
+		}
+	}
+	return totalLag, nil
+}
+
+func (s *kafkaScaler) ShouldScaleToZero(ctx context.Context) (bool, error) {
+	totalLag, err := withContext(ctx, s.getTotalLag)
+	if err != nil {
+		return false, fmt.Errorf("failed to get lag for consumer group %s: %w", s.metadata.ConsumerGroup, err)
+	}
+	return s.metadata.shouldScaleToZero(float64(totalLag)), nil
+}
+
+func (s *kafkaScaler) ShouldScaleFromZero(ctx context.Context) (bool, error) {
+	totalLag, err := withContext(ctx, s.getTotalLag)
+	if err != nil {
+		return true, fmt.Errorf("failed to get lag for consumer group %s: %w", s.metadata.ConsumerGroup, err)
+	}
+	return s.metadata.shouldScaleFromZero(float64(totalLag)), nil
+}
+
+func (s *kafkaScaler) IsHealthy(ctx context.Context) (bool, error) {
+	return withContext(ctx, func() (bool, error) {
+		kafka, err := s.connect()
+		if err != nil {
+			return false, err
+		}
+		return len(kafka.client.Brokers()) > 0, nil
+	})
+}
+
+func (s *kafkaScaler) Close(_ context.Context) error {
+	s.mu.Lock()
+	client := s.client
+	s.closed = true
+	s.mu.Unlock()
+
+	// The client is shared with the other scalers through the cache, which closes it once it is unused
+	if client != nil && !s.cache.shared() {
+		if err := client.Close(); err != nil {
+			return fmt.Errorf("failed to close kafka client: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testKafkaTopic = "jobs"
+	testKafkaGroup = "job-consumers"
+)
+
+// newMockKafkaBroker starts an in-process broker serving one topic with two partitions,
+// where the consumer group has committed the given offsets and the log ends at latestOffset
+func newMockKafkaBroker(t *testing.T, latestOffset int64, committedOffsets [2]int64) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetController(broker.BrokerID()).
+			SetLeader(testKafkaTopic, 0, broker.BrokerID()).
+			SetLeader(testKafkaTopic, 1, broker.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset(testKafkaTopic, 0, sarama.OffsetNewest, latestOffset).
+			SetOffset(testKafkaTopic, 1, sarama.OffsetNewest, latestOffset),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, testKafkaGroup, broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset(testKafkaGroup, testKafkaTopic, 0, committedOffsets[0], "", sarama.ErrNoError).
+			SetOffset(testKafkaGroup, testKafkaTopic, 1, committedOffsets[1], "", sarama.ErrNoError).
+			SetError(sarama.ErrNoError),
+	})
+	return broker
+}
+
+func TestKafkaScaler(t *testing.T) {
+	tests := []struct {
+		name                string
+		latestOffset        int64
+		committedOffsets    [2]int64
+		extraMetadata       string
+		expectScaleToZero   bool
+		expectScaleFromZero bool
+	}{
+		{
+			name:                "No lag",
+			latestOffset:        100,
+			committedOffsets:    [2]int64{100, 100},
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "Lag on one partition",
+			latestOffset:        100,
+			committedOffsets:    [2]int64{100, 90},
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "Lag below threshold",
+			latestOffset:        100,
+			committedOffsets:    [2]int64{95, 95},
+			extraMetadata:       `, "threshold": "11"`,
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "Lag at threshold",
+			latestOffset:        100,
+			committedOffsets:    [2]int64{95, 95},
+			extraMetadata:       `, "threshold": "10"`,
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "Lag between deactivationThreshold and activationThreshold",
+			latestOffset:        100,
+			committedOffsets:    [2]int64{95, 95},
+			extraMetadata:       `, "deactivationThreshold": "5", "activationThreshold": "20"`,
+			expectScaleToZero:   false,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "No committed offset",
+			latestOffset:        100,
+			committedOffsets:    [2]int64{100, invalidOffset},
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "No committed offset with scale to zero on invalid offset",
+			latestOffset:        100,
+			committedOffsets:    [2]int64{100, invalidOffset},
+			extraMetadata:       `, "scaleToZeroOnInvalidOffset": "true"`,
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newMockKafkaBroker(t, tt.latestOffset, tt.committedOffsets)
+			defer broker.Close()
+
+			metadata := json.RawMessage(fmt.Sprintf(`{"bootstrapServers": "%s", "consumerGroup": "%s", "topic": "%s"%s}`,
+				broker.Addr(), testKafkaGroup, testKafkaTopic, tt.extraMetadata))
+			scaler, err := NewKafkaScaler(context.Background(), fake.NewSimpleClientset(), "jobs", metadata, nil)
+			require.NoError(t, err)
+			defer scaler.Close(context.Background())
+
+			healthy, err := scaler.IsHealthy(context.Background())
+			require.NoError(t, err)
+			assert.True(t, healthy)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleFromZero, scaleFromZero)
+		})
+	}
+}
+
+func TestNewKafkaScalerInvalidMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+	}{
+		{name: "Missing bootstrap servers", metadata: `{"consumerGroup": "group"}`},
+		{name: "Missing consumer group", metadata: `{"bootstrapServers": "localhost:9092"}`},
+		{name: "Invalid threshold", metadata: `{"bootstrapServers": "localhost:9092", "consumerGroup": "group", "threshold": "high"}`},
+		{name: "Unsupported sasl", metadata: `{"bootstrapServers": "localhost:9092", "consumerGroup": "group", "sasl": "gssapi", "authSecretRef": "kafka-auth"}`},
+		{name: "Sasl without credentials", metadata: `{"bootstrapServers": "localhost:9092", "consumerGroup": "group", "sasl": "plaintext"}`},
+		{name: "Credentials without sasl", metadata: `{"bootstrapServers": "localhost:9092", "consumerGroup": "group", "authSecretRef": "kafka-auth"}`},
+		{name: "Missing auth secret", metadata: `{"bootstrapServers": "localhost:9092", "consumerGroup": "group", "sasl": "plaintext", "authSecretRef": "missing"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKafkaScaler(context.Background(), fake.NewSimpleClientset(), "jobs", json.RawMessage(tt.metadata), nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKafkaScalerSASL(t *testing.T) {
+	tests := []struct {
+		name        string
+		authError   sarama.KError
+		expectError bool
+	}{
+		{name: "Authenticated", authError: sarama.ErrNoError},
+		{name: "Authentication failed", authError: sarama.ErrSASLAuthenticationFailed, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newMockKafkaBroker(t, 100, [2]int64{100, 100})
+			defer broker.Close()
+			broker.SetHandlerByMap(map[string]sarama.MockResponse{
+				"SaslHandshakeRequest":    sarama.NewMockSaslHandshakeResponse(t).SetEnabledMechanisms([]string{sarama.SASLTypePlaintext}),
+				"SaslAuthenticateRequest": sarama.NewMockSaslAuthenticateResponse(t).SetError(tt.authError),
+				"MetadataRequest": sarama.NewMockMetadataResponse(t).
+					SetBroker(broker.Addr(), broker.BrokerID()).
+					SetController(broker.BrokerID()),
+			})
+
+			kClient := fake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka-auth", Namespace: "jobs"},
+				Data:       map[string][]byte{secretKeyUsername: []byte("elasti"), secretKeyPassword: []byte("secret")},
+			})
+			metadata := json.RawMessage(fmt.Sprintf(`{"bootstrapServers": "%s", "consumerGroup": "%s", "sasl": "plaintext", "authSecretRef": "kafka-auth"}`,
+				broker.Addr(), testKafkaGroup))
+			scaler, err := NewKafkaScaler(context.Background(), kClient, "jobs", metadata, nil)
+			require.NoError(t, err)
+			defer scaler.Close(context.Background())
+
+			healthy, err := scaler.IsHealthy(context.Background())
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, healthy)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, healthy)
+
+			var authBytes []byte
+			for _, exchange := range broker.History() {
+				if request, ok := exchange.Request.(*sarama.SaslAuthenticateRequest); ok {
+					authBytes = request.SaslAuthBytes
+				}
+			}
+			assert.Equal(t, "\x00elasti\x00secret", string(authBytes))
+		})
+	}
+}
+
+func TestKafkaScalerRespectsContext(t *testing.T) {
+	// The broker accepts connections but never answers, like a stuck broker
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	metadata := json.RawMessage(fmt.Sprintf(`{"bootstrapServers": "%s", "consumerGroup": "%s"}`, listener.Addr(), testKafkaGroup))
+	scaler, err := NewKafkaScaler(context.Background(), fake.NewSimpleClientset(), "jobs", metadata, nil)
+	require.NoError(t, err)
+	defer scaler.Close(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = scaler.ShouldScaleToZero(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), kafkaClientTimeout)
+}
+
+func TestKafkaScalerSharedClient(t *testing.T) {
+	broker := newMockKafkaBroker(t, 100, [2]int64{100, 90})
+	defer broker.Close()
+	cache := NewCache(10 * time.Second)
+
+	metadata := json.RawMessage(fmt.Sprintf(`{"bootstrapServers": "%s", "consumerGroup": "%s", "topic": "%s"}`,
+		broker.Addr(), testKafkaGroup, testKafkaTopic))
+	var clients []*kafkaClient
+	for range 2 {
+		scaler, err := NewKafkaScaler(context.Background(), fake.NewSimpleClientset(), "jobs", metadata, cache)
+		require.NoError(t, err)
+		scaleToZero, err := scaler.ShouldScaleToZero(context.Background())
+		require.NoError(t, err)
+		assert.False(t, scaleToZero)
+		require.NoError(t, scaler.Close(context.Background()))
+		clients = append(clients, scaler.(*kafkaScaler).client)
+	}
+
+	// The client outlives the scalers, and is reused by the next ones
+	assert.Same(t, clients[0], clients[1])
+	assert.False(t, clients[0].client.Closed())
+
+	// It is closed once it isn't used anymore
+	cache.now = func() time.Time { return time.Now().Add(2 * clientIdleTimeout) }
+	cache.EvictExpired()
+	assert.True(t, clients[0].client.Closed())
+}
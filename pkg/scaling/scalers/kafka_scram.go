@@ -0,0 +1,164 @@
+package scalers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// scramClient implements the client side of the SCRAM authentication exchange (RFC 5802) for sarama,
+// without channel binding
+type scramClient struct {
+	hash func() hash.Hash
+	// nonce returns the client nonce, it is replaced in tests
+	nonce func() (string, error)
+
+	username string
+	password string
+	authzID  string
+
+	step            int
+	gs2Header       string
+	clientNonce     string
+	clientFirstBare string
+	serverSignature []byte
+}
+
+func newSCRAMClientGenerator(mechanism sarama.SASLMechanism) func() sarama.SCRAMClient {
+	hashFunc := sha256.New
+	if mechanism == sarama.SASLTypeSCRAMSHA512 {
+		hashFunc = sha512.New
+	}
+	return func() sarama.SCRAMClient {
+		return &scramClient{hash: hashFunc, nonce: newSCRAMNonce}
+	}
+}
+
+func newSCRAMNonce() (string, error) {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate SCRAM nonce: %w", err)
+	}
+	return base64.RawStdEncoding.EncodeToString(nonce), nil
+}
+
+func (c *scramClient) Begin(username, password, authzID string) error {
+	c.username = username
+	c.password = password
+	c.authzID = authzID
+	c.step = 0
+	return nil
+}
+
+// Step returns the client-first message, then the client-final message for the server-first message,
+// and finally checks the signature in the server-final message
+func (c *scramClient) Step(challenge string) (string, error) {
+	c.step++
+	switch c.step {
+	case 1:
+		return c.clientFirst()
+	case 2:
+		return c.clientFinal(challenge)
+	case 3:
+		return "", c.verifyServerFinal(challenge)
+	default:
+		return "", errors.New("SCRAM exchange is already over")
+	}
+}
+
+func (c *scramClient) Done() bool {
+	return c.step >= 3
+}
+
+func (c *scramClient) clientFirst() (string, error) {
+	nonce, err := c.nonce()
+	if err != nil {
+		return "", err
+	}
+	c.gs2Header = "n,,"
+	if c.authzID != "" {
+		c.gs2Header = "n,a=" + escapeSCRAMName(c.authzID) + ","
+	}
+	c.clientNonce = nonce
+	c.clientFirstBare = "n=" + escapeSCRAMName(c.username) + ",r=" + nonce
+	return c.gs2Header + c.clientFirstBare, nil
+}
+
+func (c *scramClient) clientFinal(serverFirst string) (string, error) {
+	attributes := parseSCRAMAttributes(serverFirst)
+	if message, ok := attributes["e"]; ok {
+		return "", fmt.Errorf("SCRAM authentication failed: %s", message)
+	}
+	serverNonce := attributes["r"]
+	if !strings.HasPrefix(serverNonce, c.clientNonce) || len(serverNonce) == len(c.clientNonce) {
+		return "", errors.New("invalid SCRAM server nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(attributes["s"])
+	if err != nil || len(salt) == 0 {
+		return "", errors.New("invalid SCRAM salt")
+	}
+	iterations, err := strconv.Atoi(attributes["i"])
+	if err != nil || iterations < 1 {
+		return "", errors.New("invalid SCRAM iteration count")
+	}
+
+	saltedPassword := pbkdf2.Key([]byte(c.password), salt, iterations, c.hash().Size(), c.hash)
+	clientKey := c.hmac(saltedPassword, "Client Key")
+	storedKey := c.hash()
+	storedKey.Write(clientKey)
+
+	clientFinalWithoutProof := "c=" + base64.StdEncoding.EncodeToString([]byte(c.gs2Header)) + ",r=" + serverNonce
+	authMessage := c.clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof
+	clientSignature := c.hmac(storedKey.Sum(nil), authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ clientSignature[i]
+	}
+	c.serverSignature = c.hmac(c.hmac(saltedPassword, "Server Key"), authMessage)
+
+	return clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+func (c *scramClient) verifyServerFinal(serverFinal string) error {
+	attributes := parseSCRAMAttributes(serverFinal)
+	if message, ok := attributes["e"]; ok {
+		return fmt.Errorf("SCRAM authentication failed: %s", message)
+	}
+	signature, err := base64.StdEncoding.DecodeString(attributes["v"])
+	if err != nil || !hmac.Equal(signature, c.serverSignature) {
+		return errors.New("invalid SCRAM server signature")
+	}
+	return nil
+}
+
+func (c *scramClient) hmac(key []byte, message string) []byte {
+	mac := hmac.New(c.hash, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// escapeSCRAMName escapes the characters which delimit the attributes of a SCRAM message
+func escapeSCRAMName(name string) string {
+	return strings.NewReplacer("=", "=3D", ",", "=2C").Replace(name)
+}
+
+// parseSCRAMAttributes parses a SCRAM message made of comma separated key=value attributes
+func parseSCRAMAttributes(message string) map[string]string {
+	attributes := make(map[string]string)
+	for _, attribute := range strings.Split(message, ",") {
+		if key, value, ok := strings.Cut(attribute, "="); ok {
+			attributes[key] = value
+		}
+	}
+	return attributes
+}
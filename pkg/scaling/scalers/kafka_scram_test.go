@@ -0,0 +1,61 @@
+package scalers
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSCRAMClient follows the SCRAM-SHA-256 exchange of RFC 7677
+func TestSCRAMClient(t *testing.T) {
+	newClient := func() *scramClient {
+		client := &scramClient{hash: sha256.New, nonce: func() (string, error) { return "rOprNGfwEbeRWgbNEkqO", nil }}
+		require.NoError(t, client.Begin("user", "pencil", ""))
+		return client
+	}
+	serverFirst := "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+
+	client := newClient()
+	clientFirst, err := client.Step("")
+	require.NoError(t, err)
+	assert.Equal(t, "n,,n=user,r=rOprNGfwEbeRWgbNEkqO", clientFirst)
+	assert.False(t, client.Done())
+
+	clientFinal, err := client.Step(serverFirst)
+	require.NoError(t, err)
+	assert.Equal(t, "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", clientFinal)
+	assert.False(t, client.Done())
+
+	_, err = client.Step("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=")
+	require.NoError(t, err)
+	assert.True(t, client.Done())
+
+	tests := []struct {
+		name        string
+		serverFirst string
+		serverFinal string
+	}{
+		{name: "Server nonce not extending the client nonce", serverFirst: "r=other,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"},
+		{name: "Invalid iteration count", serverFirst: "r=rOprNGfwEbeRWgbNEkqO%hvY,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=none"},
+		{name: "Server error", serverFirst: "e=other-error"},
+		{name: "Invalid server signature", serverFirst: serverFirst, serverFinal: "v=aW52YWxpZA=="},
+		{name: "Server error in final message", serverFirst: serverFirst, serverFinal: "e=invalid-proof"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient()
+			_, err := client.Step("")
+			require.NoError(t, err)
+			_, err = client.Step(tt.serverFirst)
+			if tt.serverFinal == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_, err = client.Step(tt.serverFinal)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	kubernetesResourceListLimit = 500
+)
+
+type kubernetesResourceScaler struct {
+	metadata  *kubernetesResourceMetadata
+	client    dynamic.Interface
+	gvr       schema.GroupVersionResource
+	namespace string
+	cache     *Cache
+}
+
+type kubernetesResourceMetadata struct {
+	// APIVersion is the group and version of the resource, e.g. batch/v1 or v1 for the core group
+	APIVersion string `json:"apiVersion"`
+	// Resource is the plural name of the resource, e.g. jobs, pods or workflows
+	Resource      string `json:"resource"`
+	LabelSelector string `json:"labelSelector"`
+	FieldSelector string `json:"fieldSelector"`
+	// ExcludeFinished skips the objects which have finished, i.e. with a Succeeded, Failed or Error phase
+	// or with a Complete or Failed condition, like completed Jobs or Pods
+	ExcludeFinished bool `json:"excludeFinished,string"`
+	// thresholdMetadata is compared with the number of objects, the threshold is 1 by default
+	// so the service is idle once there are none
+	thresholdMetadata
+}
+
+// NewKubernetesResourceScaler creates a scaler counting the objects of a resource in the namespace of the ElastiService
+func NewKubernetesResourceScaler(client dynamic.Interface, namespace string, metadata json.RawMessage, cache *Cache) (Scaler, error) {
+	parsedMetadata, err := parseKubernetesResourceMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kubernetes-resource scaler: %w", err)
+	}
+	if client == nil {
+		return nil, fmt.Errorf("error creating kubernetes-resource scaler: no dynamic client")
+	}
+
+	gv, err := schema.ParseGroupVersion(parsedMetadata.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kubernetes-resource scaler: %w", err)
+	}
+
+	return &kubernetesResourceScaler{
+		metadata:  parsedMetadata,
+		client:    client,
+		gvr:       gv.WithResource(parsedMetadata.Resource),
+		namespace: namespace,
+		cache:     cache,
+	}, nil
+}
+
+func parseKubernetesResourceMetadata(jsonMetadata json.RawMessage) (*kubernetesResourceMetadata, error) {
+	metadata := &kubernetesResourceMetadata{}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata.APIVersion == "" {
+		return nil, fmt.Errorf("apiVersion is required")
+	}
+	if _, err := schema.ParseGroupVersion(metadata.APIVersion); err != nil {
+		return nil, fmt.Errorf("invalid apiVersion: %w", err)
+	}
+	if metadata.Resource == "" {
+		return nil, fmt.Errorf("resource is required")
+	}
+	if _, err := labels.Parse(metadata.LabelSelector); err != nil {
+		return nil, fmt.Errorf("invalid labelSelector: %w", err)
+	}
+	if _, err := fields.ParseSelector(metadata.FieldSelector); err != nil {
+		return nil, fmt.Errorf("invalid fieldSelector: %w", err)
+	}
+	metadata.defaultThreshold(1)
+	return metadata, nil
+}
+
+// getCount returns the number of matching objects,
+// shared with the other scalers counting the same objects through the cache
+func (s *kubernetesResourceScaler) getCount(ctx context.Context) (int64, error) {
+	key := hashValues(s.gvr.String(), s.namespace, s.metadata.LabelSelector, s.metadata.FieldSelector, fmt.Sprint(s.metadata.ExcludeFinished))
+	count, err := s.cache.getResult(key, func() (float64, error) {
+		count, err := s.countObjects(ctx)
+		return float64(count), err
+	})
+	return int64(count), err
+}
+
+func (s *kubernetesResourceScaler) countObjects(ctx context.Context) (int64, error) {
+	opts := metav1.ListOptions{
+		LabelSelector: s.metadata.LabelSelector,
+		FieldSelector: s.metadata.FieldSelector,
+		Limit:         kubernetesResourceListLimit,
+	}
+
+	var count int64
+	for {
+		list, err := s.client.Resource(s.gvr).Namespace(s.namespace).List(ctx, opts)
+		if err != nil {
+			return -1, fmt.Errorf("failed to list %s in namespace %s: %w", s.gvr.String(), s.namespace, err)
+		}
+		for i := range list.Items {
+			if s.metadata.ExcludeFinished && isFinished(&list.Items[i]) {
+				continue
+			}
+			count++
+		}
+		if list.GetContinue() == "" {
+			return count, nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+// isFinished reports whether the object has run to completion, based on the status conventions
+// of Pods, Jobs and workflow engines like Argo Workflows
+func isFinished(obj *unstructured.Unstructured) bool {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Succeeded", "Failed", "Error":
+		return true
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["status"] != string(metav1.ConditionTrue) {
+			continue
+		}
+		switch condition["type"] {
+		case "Complete", "Failed":
+			return true
+		}
+	}
+	return false
+}
+
+func (s *kubernetesResourceScaler) ShouldScaleToZero(ctx context.Context) (bool, error) {
+	count, err := s.getCount(ctx)
+	if err != nil {
+		return false, err
+	}
+	return s.metadata.shouldScaleToZero(float64(count)), nil
+}
+
+func (s *kubernetesResourceScaler) ShouldScaleFromZero(ctx context.Context) (bool, error) {
+	count, err := s.getCount(ctx)
+	if err != nil {
+		return true, err
+	}
+	return s.metadata.shouldScaleFromZero(float64(count)), nil
+}
+
+func (s *kubernetesResourceScaler) Close(_ context.Context) error {
+	return nil
+}
+
+// IsHealthy reports whether the objects can be listed, e.g. the resource exists and the operator is allowed to list it
+func (s *kubernetesResourceScaler) IsHealthy(ctx context.Context) (bool, error) {
+	if _, err := s.getCount(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,210 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestJob(name, namespace, app string, status map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]interface{}{"app": app},
+		},
+		"status": status,
+	}}
+}
+
+func TestParseKubernetesResourceMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    string
+		expectError bool
+	}{
+		{
+			name:     "Valid metadata",
+			metadata: `{"apiVersion": "batch/v1", "resource": "jobs", "labelSelector": "app=api", "excludeFinished": "true"}`,
+		},
+		{
+			name:     "Core group",
+			metadata: `{"apiVersion": "v1", "resource": "pods", "fieldSelector": "status.phase=Running", "threshold": "2"}`,
+		},
+		{
+			name:        "Missing apiVersion",
+			metadata:    `{"resource": "jobs"}`,
+			expectError: true,
+		},
+		{
+			name:        "Invalid apiVersion",
+			metadata:    `{"apiVersion": "batch/v1/jobs", "resource": "jobs"}`,
+			expectError: true,
+		},
+		{
+			name:        "Missing resource",
+			metadata:    `{"apiVersion": "batch/v1"}`,
+			expectError: true,
+		},
+		{
+			name:        "Invalid labelSelector",
+			metadata:    `{"apiVersion": "batch/v1", "resource": "jobs", "labelSelector": "app in (api"}`,
+			expectError: true,
+		},
+		{
+			name:        "Invalid threshold",
+			metadata:    `{"apiVersion": "batch/v1", "resource": "jobs", "threshold": "high"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseKubernetesResourceMetadata(json.RawMessage(tt.metadata))
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKubernetesResourceScaler(t *testing.T) {
+	ctx := context.Background()
+	jobs := schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{jobs: "JobList", pods: "PodList"},
+		newTestJob("export", "shop", "api", map[string]interface{}{"active": int64(1)}),
+		newTestJob("import", "shop", "api", map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Complete", "status": "True"}},
+		}),
+		newTestJob("cleanup", "shop", "cron", map[string]interface{}{"active": int64(1)}),
+		newTestJob("report", "other", "api", map[string]interface{}{"active": int64(1)}),
+	)
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(pods.GroupResource(), "", fmt.Errorf("not allowed"))
+	})
+
+	tests := []struct {
+		name              string
+		metadata          string
+		expectScaleToZero bool
+		expectError       bool
+	}{
+		{
+			name:              "All the jobs in the namespace at threshold",
+			metadata:          `{"apiVersion": "batch/v1", "resource": "jobs", "threshold": "3"}`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Jobs matching the labelSelector",
+			metadata:          `{"apiVersion": "batch/v1", "resource": "jobs", "labelSelector": "app=api", "threshold": "2"}`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Running jobs matching the labelSelector",
+			metadata:          `{"apiVersion": "batch/v1", "resource": "jobs", "labelSelector": "app=api", "excludeFinished": "true", "threshold": "2"}`,
+			expectScaleToZero: true,
+		},
+		{
+			name:              "No matching jobs",
+			metadata:          `{"apiVersion": "batch/v1", "resource": "jobs", "labelSelector": "app=web"}`,
+			expectScaleToZero: true,
+		},
+		{
+			name:        "Forbidden resource",
+			metadata:    `{"apiVersion": "v1", "resource": "pods"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaler, err := NewKubernetesResourceScaler(client, "shop", json.RawMessage(tt.metadata), nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			healthy, err := scaler.IsHealthy(ctx)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, healthy)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, healthy)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, !tt.expectScaleToZero, scaleFromZero)
+		})
+	}
+}
+
+func TestIsFinished(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         map[string]interface{}
+		expectFinished bool
+	}{
+		{
+			name:           "Running pod",
+			status:         map[string]interface{}{"phase": "Running"},
+			expectFinished: false,
+		},
+		{
+			name:           "Succeeded pod",
+			status:         map[string]interface{}{"phase": "Succeeded"},
+			expectFinished: true,
+		},
+		{
+			name:           "Argo workflow in error",
+			status:         map[string]interface{}{"phase": "Error"},
+			expectFinished: true,
+		},
+		{
+			name: "Failed job",
+			status: map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Failed", "status": "True"}},
+			},
+			expectFinished: true,
+		},
+		{
+			name: "Suspended job",
+			status: map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Suspended", "status": "True"}},
+			},
+			expectFinished: false,
+		},
+		{
+			name:           "No status",
+			expectFinished: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if tt.status != nil {
+				obj.Object["status"] = tt.status
+			}
+			assert.Equal(t, tt.expectFinished, isFinished(obj))
+		})
+	}
+}
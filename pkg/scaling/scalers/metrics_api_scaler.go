@@ -0,0 +1,184 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+type metricsAPIScaler struct {
+	metadata   *metricsAPIMetadata
+	httpClient *http.Client
+	cache      *Cache
+	// cacheKey identifies the endpoint, the headers and the credentials the requests are sent with
+	cacheKey string
+}
+
+type metricsAPIMetadata struct {
+	// URL is the HTTP endpoint returning a JSON document, it is fetched with a GET request
+	URL string `json:"url"`
+	// ValueLocation is the JSONPath of the value in the JSON document, e.g. {.sessions.active}
+	// The value must be a number or a string holding a number
+	ValueLocation string `json:"valueLocation"`
+	thresholdMetadata
+	httpMetadata
+}
+
+func NewMetricsAPIScaler(ctx context.Context, kClient kubernetes.Interface, namespace string, metadata json.RawMessage, cache *Cache) (Scaler, error) {
+	parsedMetadata, err := parseMetricsAPIMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating metrics-api scaler: %w", err)
+	}
+
+	auth, err := resolveAuthConfig(ctx, kClient, namespace, &parsedMetadata.authMetadata, cache)
+	if err != nil {
+		return nil, fmt.Errorf("error creating metrics-api scaler: %w", err)
+	}
+
+	return &metricsAPIScaler{
+		metadata:   parsedMetadata,
+		httpClient: newHTTPClient(auth, parsedMetadata.Headers, httpClientTimeout, cache),
+		cache:      cache,
+		cacheKey:   hashValues(parsedMetadata.URL, parsedMetadata.headersKey(), auth.cacheKey()),
+	}, nil
+}
+
+func parseMetricsAPIMetadata(jsonMetadata json.RawMessage) (*metricsAPIMetadata, error) {
+	metadata := &metricsAPIMetadata{}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if metadata.ValueLocation == "" {
+		return nil, fmt.Errorf("valueLocation is required")
+	}
+	if _, err := parseValueLocation(metadata.ValueLocation); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// parseValueLocation parses the JSONPath, the surrounding braces are optional
+func parseValueLocation(valueLocation string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(valueLocation, "{") {
+		valueLocation = "{" + valueLocation + "}"
+	}
+	valuePath := jsonpath.New("valueLocation")
+	if err := valuePath.Parse(valueLocation); err != nil {
+		return nil, fmt.Errorf("invalid valueLocation: %w", err)
+	}
+	return valuePath, nil
+}
+
+// getValue returns the value at the valueLocation in the response of the endpoint,
+// shared with the other scalers fetching the same endpoint through the cache
+func (s *metricsAPIScaler) getValue(ctx context.Context) (float64, error) {
+	return s.cache.getResult(s.cacheKey+"\x00"+s.metadata.ValueLocation, func() (float64, error) {
+		return s.fetchValue(ctx)
+	})
+}
+
+func (s *metricsAPIScaler) fetchValue(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.metadata.URL, nil)
+	if err != nil {
+		return -1, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, fmt.Errorf("failed to execute HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+
+	var document interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return -1, fmt.Errorf("failed to decode response from %s: %w", s.metadata.URL, err)
+	}
+
+	// A parsed JSONPath keeps state while it's evaluated, so it isn't shared between requests
+	valuePath, err := parseValueLocation(s.metadata.ValueLocation)
+	if err != nil {
+		return -1, err
+	}
+	results, err := valuePath.FindResults(document)
+	if err != nil {
+		return -1, fmt.Errorf("failed to find %s in response from %s: %w", s.metadata.ValueLocation, s.metadata.URL, err)
+	}
+
+	var values []reflect.Value
+	for _, result := range results {
+		values = append(values, result...)
+	}
+	if len(values) != 1 {
+		return -1, fmt.Errorf("%s matched %d values in response from %s, expected exactly one", s.metadata.ValueLocation, len(values), s.metadata.URL)
+	}
+	return parseMetricValue(values[0].Interface())
+}
+
+// parseMetricValue converts a value decoded from JSON to a number
+func parseMetricValue(value interface{}) (float64, error) {
+	var v float64
+	switch value := value.(type) {
+	case float64:
+		v = value
+	case string:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return -1, fmt.Errorf("failed to parse metric value: %w", err)
+		}
+		v = parsed
+	default:
+		return -1, fmt.Errorf("metric value %v is a %T, expected a number", value, value)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return -1, fmt.Errorf("metric value is %f", v)
+	}
+	return v, nil
+}
+
+func (s *metricsAPIScaler) ShouldScaleToZero(ctx context.Context) (bool, error) {
+	value, err := s.getValue(ctx)
+	if err != nil {
+		return false, err
+	}
+	return s.metadata.shouldScaleToZero(value), nil
+}
+
+func (s *metricsAPIScaler) ShouldScaleFromZero(ctx context.Context) (bool, error) {
+	value, err := s.getValue(ctx)
+	if err != nil {
+		return true, err
+	}
+	return s.metadata.shouldScaleFromZero(value), nil
+}
+
+func (s *metricsAPIScaler) Close(_ context.Context) error {
+	if s.httpClient != nil && !s.cache.shared() {
+		s.httpClient.CloseIdleConnections()
+	}
+	return nil
+}
+
+// IsHealthy reports whether the endpoint returns a value at the valueLocation
+func (s *metricsAPIScaler) IsHealthy(ctx context.Context) (bool, error) {
+	if _, err := s.getValue(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
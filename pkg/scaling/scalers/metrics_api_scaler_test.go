@@ -0,0 +1,200 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseMetricsAPIMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    string
+		expectError bool
+	}{
+		{
+			name:     "Valid metadata",
+			metadata: `{"url": "http://app:8080/admin/stats", "valueLocation": "{.sessions.active}", "threshold": "1"}`,
+		},
+		{
+			name:     "ValueLocation without braces",
+			metadata: `{"url": "http://app:8080/admin/stats", "valueLocation": ".sessions.active", "threshold": "1"}`,
+		},
+		{
+			name:        "Missing url",
+			metadata:    `{"valueLocation": "{.sessions.active}", "threshold": "1"}`,
+			expectError: true,
+		},
+		{
+			name:        "Missing valueLocation",
+			metadata:    `{"url": "http://app:8080/admin/stats", "threshold": "1"}`,
+			expectError: true,
+		},
+		{
+			name:        "Invalid valueLocation",
+			metadata:    `{"url": "http://app:8080/admin/stats", "valueLocation": "{.sessions[}", "threshold": "1"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseMetricsAPIMetadata(json.RawMessage(tt.metadata))
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMetricsAPIScaler(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/admin/stats":
+			fmt.Fprint(w, `{"sessions": {"active": 3, "idle": "0", "waiting": 1}, "jobs": [{"name": "export", "running": 2}, {"name": "import", "running": 0}]}`)
+		case "/broken":
+			fmt.Fprint(w, `{"sessions":`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	kClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "stats-auth", Namespace: "shop"},
+		Data:       map[string][]byte{"bearerToken": []byte("secret-token")},
+	})
+
+	tests := []struct {
+		name                string
+		path                string
+		valueLocation       string
+		extraMetadata       string
+		expectScaleToZero   bool
+		expectScaleFromZero bool
+		expectError         bool
+	}{
+		{
+			name:                "Number above threshold",
+			path:                "/admin/stats",
+			valueLocation:       "{.sessions.active}",
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "Number at threshold",
+			path:                "/admin/stats",
+			valueLocation:       "{.sessions.waiting}",
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "String below threshold",
+			path:                "/admin/stats",
+			valueLocation:       ".sessions.idle",
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "Filter expression",
+			path:                "/admin/stats",
+			valueLocation:       `{.jobs[?(@.name=="import")].running}`,
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "Within activation and deactivation thresholds",
+			path:                "/admin/stats",
+			valueLocation:       "{.sessions.active}",
+			extraMetadata:       `, "deactivationThreshold": "2", "activationThreshold": "5"`,
+			expectScaleToZero:   false,
+			expectScaleFromZero: false,
+		},
+		{
+			name:          "Multiple values",
+			path:          "/admin/stats",
+			valueLocation: "{.jobs[*].running}",
+			expectError:   true,
+		},
+		{
+			name:          "Missing value",
+			path:          "/admin/stats",
+			valueLocation: "{.sessions.total}",
+			expectError:   true,
+		},
+		{
+			name:          "Value is not a number",
+			path:          "/admin/stats",
+			valueLocation: "{.jobs[0].name}",
+			expectError:   true,
+		},
+		{
+			name:          "Invalid JSON",
+			path:          "/broken",
+			valueLocation: "{.sessions.active}",
+			expectError:   true,
+		},
+		{
+			name:          "Unexpected status",
+			path:          "/missing",
+			valueLocation: "{.sessions.active}",
+			expectError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := json.Marshal(map[string]string{
+				"url":           server.URL + tt.path,
+				"valueLocation": tt.valueLocation,
+				"threshold":     "1",
+				"authSecretRef": "stats-auth",
+			})
+			require.NoError(t, err)
+			if tt.extraMetadata != "" {
+				metadata = append(metadata[:len(metadata)-1], []byte(tt.extraMetadata+"}")...)
+			}
+
+			scaler, err := NewMetricsAPIScaler(ctx, kClient, "shop", metadata, nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			healthy, err := scaler.IsHealthy(ctx)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, healthy)
+
+				scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+				assert.Error(t, err)
+				assert.False(t, scaleToZero)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, healthy)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleFromZero, scaleFromZero)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultOTLPStaleAfter = 5 * time.Minute
+)
+
+type otlpScaler struct {
+	metadata *otlpMetadata
+	store    *MetricStore
+	// namespace of the ElastiService, only the series pushed for it are used
+	namespace string
+}
+
+type otlpMetadata struct {
+	// MetricName is the name of the metric pushed to the operator
+	MetricName string `json:"metricName"`
+	// Attributes are optional, only the series with all these resource or data point attributes are used.
+	// The series are always limited to the namespace of the ElastiService.
+	Attributes map[string]string `json:"attributes"`
+	thresholdMetadata
+	// aggregationMetadata reduces the matching series pushed within staleAfterSeconds
+	aggregationMetadata
+	// StaleAfterSeconds is how long a pushed value is used for, 300 seconds by default
+	StaleAfterSeconds int `json:"staleAfterSeconds,string"`
+}
+
+func NewOTLPScaler(store *MetricStore, namespace string, metadata json.RawMessage) (Scaler, error) {
+	parsedMetadata, err := parseOTLPMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating otlp scaler: %w", err)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("error creating otlp scaler: the OTLP receiver isn't running")
+	}
+
+	return &otlpScaler{
+		metadata:  parsedMetadata,
+		store:     store,
+		namespace: namespace,
+	}, nil
+}
+
+func parseOTLPMetadata(jsonMetadata json.RawMessage) (*otlpMetadata, error) {
+	metadata := &otlpMetadata{}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata.MetricName == "" {
+		return nil, fmt.Errorf("metricName is required")
+	}
+	if err := metadata.aggregationMetadata.validate(); err != nil {
+		return nil, err
+	}
+	if metadata.StaleAfterSeconds < 0 || time.Duration(metadata.StaleAfterSeconds)*time.Second > otlpSeriesRetention {
+		return nil, fmt.Errorf("staleAfterSeconds must be between 0 and %d", int(otlpSeriesRetention.Seconds()))
+	}
+	return metadata, nil
+}
+
+func (s *otlpScaler) staleAfter() time.Duration {
+	if s.metadata.StaleAfterSeconds == 0 {
+		return defaultOTLPStaleAfter
+	}
+	return time.Duration(s.metadata.StaleAfterSeconds) * time.Second
+}
+
+// getValue returns the latest pushed value of the matching series, reduced with the aggregation
+func (s *otlpScaler) getValue() (float64, error) {
+	values := s.store.values(s.namespace, s.metadata.MetricName, s.metadata.Attributes, s.staleAfter())
+	switch {
+	case len(values) == 0:
+		if s.metadata.EmptyResultAs != nil {
+			return *s.metadata.EmptyResultAs, nil
+		}
+		return -1, fmt.Errorf("no value of metric %s was pushed for namespace %s in the last %s", s.metadata.MetricName, s.namespace, s.staleAfter())
+	case s.metadata.Aggregation == "":
+		if len(values) > 1 {
+			return -1, fmt.Errorf("%d series of metric %s match, set an aggregation to combine them", len(values), s.metadata.MetricName)
+		}
+		return values[0], nil
+	default:
+		return aggregate(s.metadata.Aggregation, values), nil
+	}
+}
+
+func (s *otlpScaler) ShouldScaleToZero(_ context.Context) (bool, error) {
+	value, err := s.getValue()
+	if err != nil {
+		return false, err
+	}
+	return s.metadata.shouldScaleToZero(value), nil
+}
+
+func (s *otlpScaler) ShouldScaleFromZero(_ context.Context) (bool, error) {
+	value, err := s.getValue()
+	if err != nil {
+		return true, err
+	}
+	return s.metadata.shouldScaleFromZero(value), nil
+}
+
+func (s *otlpScaler) Close(_ context.Context) error {
+	return nil
+}
+
+// IsHealthy reports whether a value of the metric was pushed recently enough
+func (s *otlpScaler) IsHealthy(_ context.Context) (bool, error) {
+	if _, err := s.getValue(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,319 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func newTestResourceMetrics(namespace, service string, metrics ...*metricspb.Metric) *metricspb.ResourceMetrics {
+	attributes := []*commonpb.KeyValue{stringAttribute("service.name", service)}
+	if namespace != "" {
+		attributes = append(attributes, stringAttribute(OTLPNamespaceAttribute, namespace))
+	}
+	return &metricspb.ResourceMetrics{
+		Resource:     &resourcepb.Resource{Attributes: attributes},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: metrics}},
+	}
+}
+
+func newTestGauge(name string, dataPoints ...*metricspb.NumberDataPoint) *metricspb.Metric {
+	return &metricspb.Metric{Name: name, Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: dataPoints}}}
+}
+
+func TestMetricStoreConsumeOTLP(t *testing.T) {
+	now := time.Now()
+	store := NewMetricStore()
+	store.now = func() time.Time { return now }
+
+	// A trusted sender pushes the metrics of several namespaces
+	rejected, message := store.ConsumeOTLP("", []*metricspb.ResourceMetrics{
+		newTestResourceMetrics("shop", "edge",
+			newTestGauge("active_sessions",
+				&metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 3}, Attributes: []*commonpb.KeyValue{stringAttribute("region", "eu")}},
+				&metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 1.5}, Attributes: []*commonpb.KeyValue{stringAttribute("region", "us")}},
+			),
+			&metricspb.Metric{Name: "requests", Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{DataPoints: []*metricspb.NumberDataPoint{
+				{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 42}},
+			}}}},
+			&metricspb.Metric{Name: "latency", Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{DataPoints: []*metricspb.HistogramDataPoint{{}, {}}}}},
+		),
+		newTestResourceMetrics("shop", "api",
+			newTestGauge("active_sessions", &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 7}}),
+		),
+		newTestResourceMetrics("billing", "api",
+			newTestGauge("active_sessions", &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 9}}),
+		),
+		newTestResourceMetrics("", "batch",
+			newTestGauge("active_sessions", &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 5}}),
+		),
+	})
+	assert.Equal(t, int64(3), rejected)
+	assert.Contains(t, message, "latency")
+	assert.Contains(t, message, OTLPNamespaceAttribute)
+
+	assert.ElementsMatch(t, []float64{3, 1.5, 7}, store.values("shop", "active_sessions", nil, time.Minute))
+	assert.ElementsMatch(t, []float64{3, 1.5}, store.values("shop", "active_sessions", map[string]string{"service.name": "edge"}, time.Minute))
+	assert.ElementsMatch(t, []float64{3}, store.values("shop", "active_sessions", map[string]string{"service.name": "edge", "region": "eu"}, time.Minute))
+	assert.ElementsMatch(t, []float64{42}, store.values("shop", "requests", nil, time.Minute))
+	assert.Empty(t, store.values("shop", "latency", nil, time.Minute))
+
+	// The series of another namespace aren't visible, even when they have the same attributes
+	assert.ElementsMatch(t, []float64{9}, store.values("billing", "active_sessions", nil, time.Minute))
+	assert.Empty(t, store.values("billing", "requests", nil, time.Minute))
+
+	// A new push replaces the value of the series
+	store.Record("shop", "active_sessions", map[string]string{"service.name": "api", OTLPNamespaceAttribute: "shop"}, 0)
+	assert.ElementsMatch(t, []float64{0}, store.values("shop", "active_sessions", map[string]string{"service.name": "api"}, time.Minute))
+
+	now = now.Add(2 * time.Minute)
+	assert.Empty(t, store.values("shop", "active_sessions", nil, time.Minute))
+
+	now = now.Add(otlpSeriesRetention)
+	store.EvictStale()
+	assert.Empty(t, store.metrics)
+}
+
+func TestMetricStoreConsumeOTLPCallerNamespace(t *testing.T) {
+	store := NewMetricStore()
+
+	rejected, message := store.ConsumeOTLP("shop", []*metricspb.ResourceMetrics{
+		newTestResourceMetrics("", "edge", newTestGauge("active_sessions", &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 3}})),
+		newTestResourceMetrics("shop", "api", newTestGauge("active_sessions", &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 7}})),
+		newTestResourceMetrics("billing", "api", newTestGauge("active_sessions", &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 9}})),
+	})
+	assert.Equal(t, int64(1), rejected)
+	assert.Contains(t, message, "only the metrics of namespace shop can be pushed")
+
+	// The namespace defaults to the one of the sender, which can't push for another namespace
+	assert.ElementsMatch(t, []float64{3, 7}, store.values("shop", "active_sessions", nil, time.Minute))
+	assert.ElementsMatch(t, []float64{3}, store.values("shop", "active_sessions", map[string]string{OTLPNamespaceAttribute: "shop", "service.name": "edge"}, time.Minute))
+	assert.Empty(t, store.values("billing", "active_sessions", nil, time.Minute))
+}
+
+func TestMetricStoreCounters(t *testing.T) {
+	now := time.Now()
+	store := NewMetricStore()
+	store.now = func() time.Time { return now }
+	start := uint64(now.Add(-time.Hour).UnixNano())
+	at := func(seconds int) uint64 { return start + uint64(time.Duration(seconds)*time.Second) }
+
+	push := func(temporality metricspb.AggregationTemporality, dataPoints ...*metricspb.NumberDataPoint) {
+		rejected, message := store.ConsumeOTLP("shop", []*metricspb.ResourceMetrics{newTestResourceMetrics("", "edge",
+			&metricspb.Metric{Name: "requests", Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+				IsMonotonic:            true,
+				AggregationTemporality: temporality,
+				DataPoints:             dataPoints,
+			}}},
+		)})
+		require.Zero(t, rejected, message)
+	}
+	cumulative := func(seconds int, value int64) *metricspb.NumberDataPoint {
+		return &metricspb.NumberDataPoint{StartTimeUnixNano: start, TimeUnixNano: at(seconds), Value: &metricspb.NumberDataPoint_AsInt{AsInt: value}}
+	}
+
+	// The first data point of a cumulative counter only sets its baseline
+	push(metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, cumulative(3600, 5000))
+	assert.Empty(t, store.values("shop", "requests", nil, time.Minute))
+
+	push(metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, cumulative(3630, 5060))
+	assert.Equal(t, []float64{2}, store.values("shop", "requests", nil, time.Minute))
+
+	// A counter which stops increasing is idle, however large its total
+	push(metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, cumulative(3660, 5060))
+	assert.Equal(t, []float64{0}, store.values("shop", "requests", nil, time.Minute))
+
+	// An older data point doesn't change the rate
+	push(metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, cumulative(3630, 5060))
+	assert.Equal(t, []float64{0}, store.values("shop", "requests", nil, time.Minute))
+
+	// A reset counter counts from its new start
+	restart := at(3670)
+	push(metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		&metricspb.NumberDataPoint{StartTimeUnixNano: restart, TimeUnixNano: restart + uint64(10*time.Second), Value: &metricspb.NumberDataPoint_AsInt{AsInt: 30}})
+	assert.Equal(t, []float64{3}, store.values("shop", "requests", nil, time.Minute))
+
+	// The increase of a delta counter is spread over its interval
+	store = NewMetricStore()
+	push(metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+		&metricspb.NumberDataPoint{StartTimeUnixNano: at(0), TimeUnixNano: at(20), Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 10}})
+	assert.Equal(t, []float64{0.5}, store.values("shop", "requests", nil, time.Minute))
+
+	// Delta up-down counters don't report a level
+	rejected, message := store.ConsumeOTLP("shop", []*metricspb.ResourceMetrics{newTestResourceMetrics("", "edge",
+		&metricspb.Metric{Name: "sessions", Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+			DataPoints:             []*metricspb.NumberDataPoint{{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 1}}},
+		}}},
+	)})
+	assert.Equal(t, int64(1), rejected)
+	assert.Contains(t, message, "sessions")
+}
+
+func TestMetricStoreMaxSeries(t *testing.T) {
+	now := time.Now()
+	store := NewMetricStore()
+	store.now = func() time.Time { return now }
+	store.maxSeriesPerNamespace = 2
+
+	require.NoError(t, store.Record("shop", "active_sessions", map[string]string{"pod": "a"}, 1))
+	require.NoError(t, store.Record("shop", "queue_depth", map[string]string{"pod": "a"}, 1))
+	assert.ErrorIs(t, store.Record("shop", "active_sessions", map[string]string{"pod": "b"}, 1), ErrTooManySeries)
+	// The existing series are still updated, and the other namespaces have their own limit
+	assert.NoError(t, store.Record("shop", "active_sessions", map[string]string{"pod": "a"}, 2))
+	assert.NoError(t, store.Record("billing", "active_sessions", map[string]string{"pod": "b"}, 1))
+
+	rejected, message := store.ConsumeOTLP("shop", []*metricspb.ResourceMetrics{
+		newTestResourceMetrics("", "edge", newTestGauge("requests", &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: 3}})),
+	})
+	assert.Equal(t, int64(1), rejected)
+	assert.Contains(t, message, ErrTooManySeries.Error())
+
+	// Evicted series make room for new ones
+	now = now.Add(otlpSeriesRetention + time.Minute)
+	store.EvictStale()
+	assert.NoError(t, store.Record("shop", "active_sessions", map[string]string{"pod": "b"}, 1))
+}
+
+func TestOTLPScaler(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMetricStore()
+	store.now = func() time.Time { return now }
+	store.Record("shop", "active_sessions", map[string]string{"service.name": "edge", "region": "eu"}, 3)
+	store.Record("shop", "active_sessions", map[string]string{"service.name": "edge", "region": "us"}, 0)
+	store.Record("shop", "queue_depth", map[string]string{"service.name": "worker"}, 0)
+	store.Record("shop", "running_exports", map[string]string{"service.name": "worker"}, 1)
+	// A series pushed for another namespace doesn't keep the worker awake
+	store.Record("billing", "queue_depth", map[string]string{"service.name": "worker"}, 50)
+
+	tests := []struct {
+		name                string
+		metadata            string
+		expectScaleToZero   bool
+		expectScaleFromZero bool
+		expectError         bool
+	}{
+		{
+			name:                "Single series above threshold",
+			metadata:            `{"metricName": "active_sessions", "attributes": {"region": "eu"}, "threshold": "1"}`,
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "Single series below threshold",
+			metadata:            `{"metricName": "queue_depth", "threshold": "1"}`,
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "Single series at threshold",
+			metadata:            `{"metricName": "running_exports", "threshold": "1"}`,
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "Aggregated series",
+			metadata:            `{"metricName": "active_sessions", "attributes": {"service.name": "edge"}, "aggregation": "max", "threshold": "1"}`,
+			expectScaleToZero:   false,
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "Within activation and deactivation thresholds",
+			metadata:            `{"metricName": "active_sessions", "aggregation": "sum", "threshold": "1", "deactivationThreshold": "2", "activationThreshold": "5"}`,
+			expectScaleToZero:   false,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "No series with emptyResultAs",
+			metadata:            `{"metricName": "active_sessions", "attributes": {"region": "ap"}, "threshold": "1", "emptyResultAs": "0"}`,
+			expectScaleToZero:   true,
+			expectScaleFromZero: false,
+		},
+		{
+			name:        "No series",
+			metadata:    `{"metricName": "active_sessions", "attributes": {"region": "ap"}, "threshold": "1"}`,
+			expectError: true,
+		},
+		{
+			name:        "Multiple series without aggregation",
+			metadata:    `{"metricName": "active_sessions", "threshold": "1"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaler, err := NewOTLPScaler(store, "shop", json.RawMessage(tt.metadata))
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			healthy, err := scaler.IsHealthy(ctx)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, healthy)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, healthy)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleFromZero, scaleFromZero)
+		})
+	}
+}
+
+func TestParseOTLPMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    string
+		expectError bool
+	}{
+		{
+			name:     "Valid metadata",
+			metadata: `{"metricName": "active_sessions", "threshold": "1", "staleAfterSeconds": "60"}`,
+		},
+		{
+			name:        "Missing metricName",
+			metadata:    `{"threshold": "1"}`,
+			expectError: true,
+		},
+		{
+			name:        "Unsupported aggregation",
+			metadata:    `{"metricName": "active_sessions", "aggregation": "median"}`,
+			expectError: true,
+		},
+		{
+			name:        "staleAfterSeconds above the retention",
+			metadata:    `{"metricName": "active_sessions", "staleAfterSeconds": "7200"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseOTLPMetadata(json.RawMessage(tt.metadata))
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package scalers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+const (
+	// otlpSeriesRetention is how long the series pushed over OTLP are kept after their last push,
+	// it bounds the staleAfterSeconds of the otlp triggers
+	otlpSeriesRetention = time.Hour
+
+	// otlpMaxSeriesPerNamespace bounds the number of series kept for a namespace, so the metrics pushed
+	// for one namespace can't grow the memory of the operator without limit
+	otlpMaxSeriesPerNamespace = 1000
+
+	// OTLPNamespaceAttribute is the attribute with the namespace a series belongs to,
+	// only the otlp triggers of the ElastiServices in that namespace read it
+	OTLPNamespaceAttribute = "k8s.namespace.name"
+)
+
+// ErrTooManySeries is returned when a namespace already has the maximum number of series
+var ErrTooManySeries = errors.New("too many series")
+
+// MetricStore keeps the latest value of each series of the metrics pushed to the operator over OTLP,
+// the otlp triggers read their values from it. The series are scoped by namespace, so the metrics pushed
+// for one namespace can't keep the services of another one awake or let them scale to zero.
+type MetricStore struct {
+	now func() time.Time
+	// maxSeriesPerNamespace bounds the number of series of each namespace
+	maxSeriesPerNamespace int
+
+	mu sync.RWMutex
+	// metrics maps the namespace and name of the metric to its series, by the key of their attributes
+	metrics map[metricKey]map[string]*metricSeries
+	// seriesCount is the number of series of each namespace, across all its metrics
+	seriesCount map[string]int
+}
+
+type metricKey struct {
+	namespace string
+	name      string
+}
+
+type metricSeries struct {
+	// attributes are the resource attributes overridden by the data point attributes
+	attributes map[string]string
+	value      float64
+	// hasValue is false until the rate of a counter can be calculated, which takes two of its data points
+	hasValue bool
+	// receivedAt is when the value was pushed, the clocks of the pushing apps aren't trusted
+	receivedAt time.Time
+	// counter is the last data point of a cumulative counter, the next one is compared with it
+	counter *counterPoint
+}
+
+// counterPoint is a data point of a cumulative counter, with the times reported by the app
+type counterPoint struct {
+	start uint64
+	time  uint64
+	value float64
+}
+
+func NewMetricStore() *MetricStore {
+	return &MetricStore{
+		now:                   time.Now,
+		maxSeriesPerNamespace: otlpMaxSeriesPerNamespace,
+		metrics:               make(map[metricKey]map[string]*metricSeries),
+		seriesCount:           make(map[string]int),
+	}
+}
+
+// Record stores the value of the series of the metric in the namespace with the given attributes
+func (m *MetricStore) Record(namespace, name string, attributes map[string]string, value float64) error {
+	return m.update(namespace, name, attributes, func(series *metricSeries) {
+		series.value = value
+		series.hasValue = true
+	})
+}
+
+// recordCounter stores the per second rate of a monotonic sum, so a counter which stops increasing is idle.
+// A cumulative counter is compared with its previous data point, while the increase of a delta counter
+// is spread over its own interval.
+func (m *MetricStore) recordCounter(namespace, name string, attributes map[string]string, point counterPoint, cumulative bool) error {
+	return m.update(namespace, name, attributes, func(series *metricSeries) {
+		if !cumulative {
+			series.value, series.hasValue = rate(point.value, point.start, point.time)
+			return
+		}
+
+		previous := series.counter
+		switch {
+		case previous == nil:
+			// The first data point only sets the baseline of the counter
+			series.hasValue = false
+		case point.time <= previous.time:
+			// A data point older than the previous one doesn't change the rate
+			return
+		case point.start != previous.start || point.value < previous.value:
+			// The counter was reset, it counted the value since its new start
+			series.value, series.hasValue = rate(point.value, point.start, point.time)
+		default:
+			series.value, series.hasValue = rate(point.value-previous.value, previous.time, point.time)
+		}
+		series.counter = &point
+	})
+}
+
+// rate returns the per second rate of the increase between the two times in nanoseconds, if they make an interval
+func rate(increase float64, from, to uint64) (float64, bool) {
+	if from == 0 || to <= from {
+		return 0, false
+	}
+	return increase / time.Duration(to-from).Seconds(), true
+}
+
+// update applies the change to the series of the metric in the namespace with the given attributes,
+// creating it unless the namespace already has the maximum number of series
+func (m *MetricStore) update(namespace, name string, attributes map[string]string, change func(series *metricSeries)) error {
+	key := seriesKey(attributes)
+	receivedAt := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	series, ok := m.metrics[metricKey{namespace: namespace, name: name}]
+	if !ok {
+		series = make(map[string]*metricSeries)
+		m.metrics[metricKey{namespace: namespace, name: name}] = series
+	}
+	s, ok := series[key]
+	if !ok {
+		if m.seriesCount[namespace] >= m.maxSeriesPerNamespace {
+			if len(series) == 0 {
+				delete(m.metrics, metricKey{namespace: namespace, name: name})
+			}
+			return fmt.Errorf("%w, namespace %s already has %d series", ErrTooManySeries, namespace, m.maxSeriesPerNamespace)
+		}
+		s = &metricSeries{attributes: attributes}
+		series[key] = s
+		m.seriesCount[namespace]++
+	}
+	change(s)
+	s.receivedAt = receivedAt
+	return nil
+}
+
+// ConsumeOTLP records the gauge and sum data points of the metrics pushed by an app in the callerNamespace.
+// The data points are recorded in the namespace of their k8s.namespace.name attribute, which defaults to the
+// callerNamespace and can't be any other namespace. An empty callerNamespace is for the trusted senders, like
+// a collector pushing the metrics of all the namespaces, whose data points must all have the attribute.
+// It returns the number of data points which were rejected, along with a message describing why.
+func (m *MetricStore) ConsumeOTLP(callerNamespace string, resourceMetrics []*metricspb.ResourceMetrics) (int64, string) {
+	var rejected rejections
+	for _, rm := range resourceMetrics {
+		resourceAttributes := attributesToMap(rm.GetResource().GetAttributes())
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, metric := range sm.GetMetrics() {
+				var dataPoints []*metricspb.NumberDataPoint
+				var counter, cumulative bool
+				switch data := metric.GetData().(type) {
+				case *metricspb.Metric_Gauge:
+					dataPoints = data.Gauge.GetDataPoints()
+				case *metricspb.Metric_Sum:
+					dataPoints = data.Sum.GetDataPoints()
+					counter = data.Sum.GetIsMonotonic()
+					cumulative = data.Sum.GetAggregationTemporality() != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+					if !counter && !cumulative {
+						rejected.add("delta non-monotonic sums aren't supported", metric.GetName(), len(dataPoints))
+						continue
+					}
+				case *metricspb.Metric_Histogram:
+					rejected.add("only gauge and sum metrics are supported", metric.GetName(), len(data.Histogram.GetDataPoints()))
+				case *metricspb.Metric_ExponentialHistogram:
+					rejected.add("only gauge and sum metrics are supported", metric.GetName(), len(data.ExponentialHistogram.GetDataPoints()))
+				case *metricspb.Metric_Summary:
+					rejected.add("only gauge and sum metrics are supported", metric.GetName(), len(data.Summary.GetDataPoints()))
+				}
+
+				for _, dp := range dataPoints {
+					attributes := make(map[string]string, len(resourceAttributes)+len(dp.GetAttributes()))
+					for key, value := range resourceAttributes {
+						attributes[key] = value
+					}
+					for key, value := range attributesToMap(dp.GetAttributes()) {
+						attributes[key] = value
+					}
+					namespace := attributes[OTLPNamespaceAttribute]
+					switch {
+					case namespace == "" && callerNamespace == "":
+						rejected.add(fmt.Sprintf("the %s attribute is required", OTLPNamespaceAttribute), metric.GetName(), 1)
+						continue
+					case namespace == "":
+						namespace = callerNamespace
+						attributes[OTLPNamespaceAttribute] = namespace
+					case callerNamespace != "" && namespace != callerNamespace:
+						rejected.add(fmt.Sprintf("only the metrics of namespace %s can be pushed", callerNamespace), metric.GetName(), 1)
+						continue
+					}
+
+					var value float64
+					switch v := dp.GetValue().(type) {
+					case *metricspb.NumberDataPoint_AsDouble:
+						value = v.AsDouble
+					case *metricspb.NumberDataPoint_AsInt:
+						value = float64(v.AsInt)
+					default:
+						continue
+					}
+
+					var err error
+					if counter {
+						point := counterPoint{start: dp.GetStartTimeUnixNano(), time: dp.GetTimeUnixNano(), value: value}
+						err = m.recordCounter(namespace, metric.GetName(), attributes, point, cumulative)
+					} else {
+						err = m.Record(namespace, metric.GetName(), attributes, value)
+					}
+					if err != nil {
+						rejected.add(err.Error(), metric.GetName(), 1)
+					}
+				}
+			}
+		}
+	}
+	return rejected.count, rejected.message()
+}
+
+// rejections collects the data points rejected by ConsumeOTLP, with the names of their metrics by reason
+type rejections struct {
+	count   int64
+	reasons []string
+	metrics map[string][]string
+}
+
+func (r *rejections) add(reason, metric string, dataPoints int) {
+	if dataPoints == 0 {
+		return
+	}
+	r.count += int64(dataPoints)
+	if r.metrics == nil {
+		r.metrics = make(map[string][]string)
+	}
+	names, ok := r.metrics[reason]
+	if !ok {
+		r.reasons = append(r.reasons, reason)
+	}
+	if !slices.Contains(names, metric) {
+		r.metrics[reason] = append(names, metric)
+	}
+}
+
+// message describes why the data points were rejected, e.g. "the k8s.namespace.name attribute is required,
+// rejected the data points of active_sessions"
+func (r *rejections) message() string {
+	messages := make([]string, 0, len(r.reasons))
+	for _, reason := range r.reasons {
+		messages = append(messages, fmt.Sprintf("%s, rejected the data points of %s", reason, strings.Join(r.metrics[reason], ", ")))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// values returns the values of the series of the metric in the namespace which have all the given attributes,
+// and were pushed within maxAge
+func (m *MetricStore) values(namespace, name string, match map[string]string, maxAge time.Duration) []float64 {
+	oldest := m.now().Add(-maxAge)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var values []float64
+	for _, series := range m.metrics[metricKey{namespace: namespace, name: name}] {
+		if !series.hasValue || series.receivedAt.Before(oldest) || !hasAttributes(series.attributes, match) {
+			continue
+		}
+		values = append(values, series.value)
+	}
+	return values
+}
+
+// EvictStale removes the series which haven't been pushed for an hour, so the store doesn't grow
+// with the series of apps which stopped pushing
+func (m *MetricStore) EvictStale() {
+	oldest := m.now().Add(-otlpSeriesRetention)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for metric, series := range m.metrics {
+		for key, s := range series {
+			if s.receivedAt.Before(oldest) {
+				delete(series, key)
+				m.seriesCount[metric.namespace]--
+			}
+		}
+		if m.seriesCount[metric.namespace] <= 0 {
+			delete(m.seriesCount, metric.namespace)
+		}
+		if len(series) == 0 {
+			delete(m.metrics, metric)
+		}
+	}
+}
+
+func hasAttributes(attributes, match map[string]string) bool {
+	for key, value := range match {
+		if attributes[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// seriesKey identifies a series by its sorted attributes
+func seriesKey(attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(attributes[key])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// attributesToMap converts the OTLP attributes with scalar values to strings, other attributes are dropped
+func attributesToMap(attributes []*commonpb.KeyValue) map[string]string {
+	result := make(map[string]string, len(attributes))
+	for _, attribute := range attributes {
+		switch v := attribute.GetValue().GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			result[attribute.GetKey()] = v.StringValue
+		case *commonpb.AnyValue_IntValue:
+			result[attribute.GetKey()] = strconv.FormatInt(v.IntValue, 10)
+		case *commonpb.AnyValue_DoubleValue:
+			result[attribute.GetKey()] = strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+		case *commonpb.AnyValue_BoolValue:
+			result[attribute.GetKey()] = strconv.FormatBool(v.BoolValue)
+		}
+	}
+	return result
+}
@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -22,16 +24,22 @@ type prometheusScaler struct {
 	httpClient     *http.Client
 	metadata       *prometheusMetadata
 	cooldownPeriod time.Duration
+	cache          *Cache
+	// cacheKey identifies the server and the credentials the queries are run with
+	cacheKey string
 }
 
 type prometheusMetadata struct {
-	ServerAddress string  `json:"serverAddress"`
-	Query         string  `json:"query"`
-	Threshold     float64 `json:"threshold,string"`
-	UptimeFilter  string  `json:"uptimeFilter"`
+	ServerAddress string `json:"serverAddress"`
+	Query         string `json:"query"`
+	UptimeFilter  string `json:"uptimeFilter"`
+	thresholdMetadata
+	// aggregationMetadata reduces the series returned by the query
+	aggregationMetadata
+	httpMetadata
 }
 
-var promQueryResponse struct {
+type promQueryResponse struct {
 	Status string `json:"status"`
 	Data   struct {
 		ResultType string `json:"resultType"`
@@ -42,20 +50,30 @@ var promQueryResponse struct {
 	} `json:"data"`
 }
 
-func NewPrometheusScaler(metadata json.RawMessage, cooldownPeriod time.Duration) (Scaler, error) {
+// resultPolicy decides how the series returned by a query are reduced to a single value.
+// The zero value requires the query to return exactly one series.
+type resultPolicy struct {
+	aggregation   string
+	emptyResultAs *float64
+}
+
+func NewPrometheusScaler(ctx context.Context, kClient kubernetes.Interface, namespace string, metadata json.RawMessage, cooldownPeriod time.Duration, cache *Cache) (Scaler, error) {
 	parsedMetadata, err := parsePrometheusMetadata(metadata)
 	if err != nil {
 		return nil, fmt.Errorf("error creating prometheus scaler: %w", err)
 	}
 
-	client := &http.Client{
-		Timeout: httpClientTimeout,
+	auth, err := resolveAuthConfig(ctx, kClient, namespace, &parsedMetadata.authMetadata, cache)
+	if err != nil {
+		return nil, fmt.Errorf("error creating prometheus scaler: %w", err)
 	}
 
 	return &prometheusScaler{
 		metadata:       parsedMetadata,
-		httpClient:     client,
+		httpClient:     newHTTPClient(auth, parsedMetadata.Headers, httpClientTimeout, cache),
 		cooldownPeriod: cooldownPeriod,
+		cache:          cache,
+		cacheKey:       hashValues(parsedMetadata.ServerAddress, parsedMetadata.headersKey(), auth.cacheKey()),
 	}, nil
 }
 
@@ -65,6 +83,15 @@ func parsePrometheusMetadata(jsonMetadata json.RawMessage) (*prometheusMetadata,
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse metadata: %w", err)
 	}
+	if metadata.ServerAddress == "" {
+		return nil, fmt.Errorf("serverAddress is required")
+	}
+	if metadata.Query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	if err := metadata.aggregationMetadata.validate(); err != nil {
+		return nil, err
+	}
 	return metadata, nil
 }
 
@@ -76,63 +103,105 @@ func queryEscape(query string) string {
 	return plusEscaped
 }
 
-func (s *prometheusScaler) executePromQuery(ctx context.Context, query string) (float64, error) {
+// executePromQuery returns the result of the query reduced according to the policy,
+// shared with the other scalers querying the same server through the cache
+func (s *prometheusScaler) executePromQuery(ctx context.Context, query string, policy resultPolicy) (float64, error) {
+	return s.cache.getResult(s.cacheKey+query+"\x00"+policy.cacheKey(), func() (float64, error) {
+		values, err := s.fetchPromQuery(ctx, query)
+		if err != nil {
+			return -1, err
+		}
+		return policy.reduce(query, values)
+	})
+}
+
+// fetchPromQuery returns the value of each series returned by the query
+func (s *prometheusScaler) fetchPromQuery(ctx context.Context, query string) ([]float64, error) {
 	t := time.Now().UTC().Format(time.RFC3339)
 	queryEscaped := queryEscape(query)
 	queryURL := fmt.Sprintf("%s/api/v1/query?query=%s&time=%s", s.metadata.ServerAddress, queryEscaped, t)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", queryURL, nil)
 	if err != nil {
-		return -1, fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return -1, fmt.Errorf("failed to execute HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return -1, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+		return nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&promQueryResponse); err != nil {
-		return -1, fmt.Errorf("failed to decode Prometheus response: %w", err)
+	var response promQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode Prometheus response: %w", err)
 	}
 
-	var v float64 = -1
+	values := make([]float64, 0, len(response.Data.Result))
+	for _, result := range response.Data.Result {
+		valueLen := len(result.Value)
+		if valueLen == 0 {
+			return nil, fmt.Errorf("prometheus query %s, value list in result is empty, prometheus metrics 'prometheus' target may be lost", query)
+		} else if valueLen < 2 {
+			return nil, fmt.Errorf("prometheus query %s didn't return enough values", query)
+		}
 
-	if len(promQueryResponse.Data.Result) == 0 {
-		return -1, fmt.Errorf("prometheus query %s, result is empty, prometheus metrics 'prometheus' target may be lost", query)
-	} else if len(promQueryResponse.Data.Result) > 1 {
-		return -1, fmt.Errorf("prometheus query %s returned multiple elements", query)
+		var v float64 = -1
+		if val := result.Value[1]; val != nil {
+			str := val.(string)
+			v, err = strconv.ParseFloat(str, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse metric value: %w", err)
+			}
+		}
+
+		if math.IsInf(v, 0) {
+			return nil, fmt.Errorf("prometheus query returns %f", v)
+		}
+		values = append(values, v)
 	}
 
-	valueLen := len(promQueryResponse.Data.Result[0].Value)
-	if valueLen == 0 {
-		return -1, fmt.Errorf("prometheus query %s, value list in result is empty, prometheus metrics 'prometheus' target may be lost", s.metadata.Query)
-	} else if valueLen < 2 {
-		return -1, fmt.Errorf("prometheus query %s didn't return enough values", s.metadata.Query)
+	return values, nil
+}
+
+func (p resultPolicy) cacheKey() string {
+	if p.emptyResultAs == nil {
+		return p.aggregation
 	}
+	return fmt.Sprintf("%s/%g", p.aggregation, *p.emptyResultAs)
+}
 
-	val := promQueryResponse.Data.Result[0].Value[1]
-	if val != nil {
-		str := val.(string)
-		v, err = strconv.ParseFloat(str, 64)
-		if err != nil {
-			return -1, fmt.Errorf("failed to parse metric value: %w", err)
+// reduce returns the single value of the series returned by the query
+func (p resultPolicy) reduce(query string, values []float64) (float64, error) {
+	if len(values) == 0 {
+		if p.emptyResultAs != nil {
+			return *p.emptyResultAs, nil
 		}
+		return -1, fmt.Errorf("prometheus query %s, result is empty, prometheus metrics 'prometheus' target may be lost", query)
 	}
 
-	if math.IsInf(v, 0) {
-		return -1, fmt.Errorf("prometheus query returns %f", v)
+	if p.aggregation == "" {
+		if len(values) > 1 {
+			return -1, fmt.Errorf("prometheus query %s returned multiple elements, set an aggregation to combine them", query)
+		}
+		return values[0], nil
 	}
+	return aggregate(p.aggregation, values), nil
+}
 
-	return v, nil
+func (s *prometheusScaler) resultPolicy() resultPolicy {
+	return resultPolicy{
+		aggregation:   s.metadata.Aggregation,
+		emptyResultAs: s.metadata.EmptyResultAs,
+	}
 }
 
 func (s *prometheusScaler) ShouldScaleToZero(ctx context.Context) (bool, error) {
-	metricValue, err := s.executePromQuery(ctx, s.metadata.Query)
+	metricValue, err := s.executePromQuery(ctx, s.metadata.Query, s.resultPolicy())
 	if err != nil {
 		return false, fmt.Errorf("failed to execute prometheus query %s: %w", s.metadata.Query, err)
 	}
@@ -140,29 +209,22 @@ func (s *prometheusScaler) ShouldScaleToZero(ctx context.Context) (bool, error)
 	if metricValue == -1 {
 		return false, nil
 	}
-	if metricValue < s.metadata.Threshold {
-		return true, nil
-	}
-	return false, nil
+	return s.metadata.shouldScaleToZero(metricValue), nil
 }
 
 func (s *prometheusScaler) ShouldScaleFromZero(ctx context.Context) (bool, error) {
-	metricValue, err := s.executePromQuery(ctx, s.metadata.Query)
+	metricValue, err := s.executePromQuery(ctx, s.metadata.Query, s.resultPolicy())
 	if err != nil {
 		return true, fmt.Errorf("failed to execute prometheus query %s: %w", s.metadata.Query, err)
 	}
 	if metricValue == -1 {
 		return true, nil
 	}
-
-	if metricValue >= s.metadata.Threshold {
-		return true, nil
-	}
-	return false, nil
+	return s.metadata.shouldScaleFromZero(metricValue), nil
 }
 
 func (s *prometheusScaler) Close(_ context.Context) error {
-	if s.httpClient != nil {
+	if s.httpClient != nil && !s.cache.shared() {
 		s.httpClient.CloseIdleConnections()
 	}
 	return nil
@@ -180,6 +242,7 @@ func (s *prometheusScaler) IsHealthy(ctx context.Context) (bool, error) {
 	metricValue, err := s.executePromQuery(
 		ctx,
 		finalUptimeQuery,
+		resultPolicy{},
 	)
 	if err != nil {
 		return false, fmt.Errorf("failed to execute prometheus query %s: %w", finalUptimeQuery, err)
@@ -0,0 +1,316 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestPrometheusHandler returns a handler answering every query with the value,
+// if the request is authorized and carries the X-Scope-OrgID header of the tenant
+func newTestPrometheusHandler(authorize func(r *http.Request) bool, tenant, value string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Scope-OrgID") != tenant {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [%d, "%s"]}]}}`, time.Now().Unix(), value)
+	}
+}
+
+func TestPrometheusScalerAuth(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		authorize   func(r *http.Request) bool
+		secretData  map[string][]byte
+		metadata    string
+		expectError bool
+	}{
+		{
+			name: "Bearer token",
+			authorize: func(r *http.Request) bool {
+				return r.Header.Get("Authorization") == "Bearer token-1"
+			},
+			secretData: map[string][]byte{secretKeyBearerToken: []byte("token-1")},
+			metadata:   `"authSecretRef": "prometheus-auth"`,
+		},
+		{
+			name: "Basic auth",
+			authorize: func(r *http.Request) bool {
+				username, password, ok := r.BasicAuth()
+				return ok && username == "elasti" && password == "secret-password"
+			},
+			secretData: map[string][]byte{secretKeyUsername: []byte("elasti"), secretKeyPassword: []byte("secret-password")},
+			metadata:   `"authSecretRef": "prometheus-auth"`,
+		},
+		{
+			name: "Missing credentials",
+			authorize: func(r *http.Request) bool {
+				return r.Header.Get("Authorization") != ""
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(newTestPrometheusHandler(tt.authorize, "tenant-1", "0"))
+			defer server.Close()
+
+			caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			kClient := fake.NewSimpleClientset(
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "prometheus-auth", Namespace: "monitoring"},
+					Data:       tt.secretData,
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "prometheus-tls", Namespace: "monitoring"},
+					Data:       map[string][]byte{secretKeyCA: caData},
+				},
+			)
+
+			metadata := json.RawMessage(fmt.Sprintf(`{"serverAddress": "%s", "query": "sum(rate(requests_total[1m]))", "threshold": "0.5",
+				"tlsSecretRef": "prometheus-tls", "headers": {"X-Scope-OrgID": "tenant-1"}`, server.URL))
+			if tt.metadata != "" {
+				metadata = append(metadata, ", "+tt.metadata...)
+			}
+			metadata = append(metadata, '}')
+
+			scaler, err := NewPrometheusScaler(ctx, kClient, "monitoring", metadata, time.Minute, nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, scaleToZero)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, scaleToZero)
+		})
+	}
+}
+
+func TestPrometheusScalerUntrustedCertificate(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewTLSServer(newTestPrometheusHandler(func(*http.Request) bool { return true }, "", "0"))
+	defer server.Close()
+
+	metadata := json.RawMessage(fmt.Sprintf(`{"serverAddress": "%s", "query": "up", "threshold": "1"}`, server.URL))
+	scaler, err := NewPrometheusScaler(ctx, fake.NewSimpleClientset(), "monitoring", metadata, time.Minute, nil)
+	require.NoError(t, err)
+	defer scaler.Close(ctx)
+
+	_, err = scaler.ShouldScaleToZero(ctx)
+	assert.Error(t, err)
+}
+
+func TestPrometheusScalerActivationThreshold(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                string
+		value               string
+		activationThreshold string
+		expectScaleFromZero bool
+	}{
+		{
+			name:                "Threshold reached without activationThreshold",
+			value:               "5",
+			expectScaleFromZero: true,
+		},
+		{
+			name:                "Below threshold without activationThreshold",
+			value:               "4",
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "Threshold reached within activationThreshold",
+			value:               "5",
+			activationThreshold: `, "activationThreshold": "20"`,
+			expectScaleFromZero: false,
+		},
+		{
+			name:                "Above activationThreshold",
+			value:               "21",
+			activationThreshold: `, "activationThreshold": "20"`,
+			expectScaleFromZero: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(newTestPrometheusHandler(func(*http.Request) bool { return true }, "", tt.value))
+			defer server.Close()
+
+			metadata := json.RawMessage(fmt.Sprintf(`{"serverAddress": "%s", "query": "sum(queue_depth)", "threshold": "5"%s}`, server.URL, tt.activationThreshold))
+			scaler, err := NewPrometheusScaler(ctx, fake.NewSimpleClientset(), "monitoring", metadata, time.Minute, nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleFromZero, scaleFromZero)
+		})
+	}
+}
+
+func TestPrometheusScalerDeactivationThreshold(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                  string
+		value                 string
+		deactivationThreshold string
+		expectScaleToZero     bool
+	}{
+		{
+			name:              "Below threshold without deactivationThreshold",
+			value:             "4",
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Threshold reached without deactivationThreshold",
+			value:             "5",
+			expectScaleToZero: false,
+		},
+		{
+			name:                  "Below threshold within deactivationThreshold",
+			value:                 "4",
+			deactivationThreshold: `, "deactivationThreshold": "2"`,
+			expectScaleToZero:     false,
+		},
+		{
+			name:                  "Below deactivationThreshold",
+			value:                 "1",
+			deactivationThreshold: `, "deactivationThreshold": "2"`,
+			expectScaleToZero:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(newTestPrometheusHandler(func(*http.Request) bool { return true }, "", tt.value))
+			defer server.Close()
+
+			metadata := json.RawMessage(fmt.Sprintf(`{"serverAddress": "%s", "query": "sum(queue_depth)", "threshold": "5"%s}`, server.URL, tt.deactivationThreshold))
+			scaler, err := NewPrometheusScaler(ctx, fake.NewSimpleClientset(), "monitoring", metadata, time.Minute, nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+		})
+	}
+}
+
+func TestPrometheusScalerResultPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name              string
+		values            []string
+		metadata          string
+		expectScaleToZero bool
+		expectError       bool
+	}{
+		{
+			name:              "Single series",
+			values:            []string{"0"},
+			expectScaleToZero: true,
+		},
+		{
+			name:        "Multiple series without aggregation",
+			values:      []string{"0", "3"},
+			expectError: true,
+		},
+		{
+			name:              "Sum of series",
+			values:            []string{"0.4", "0.4"},
+			metadata:          `, "aggregation": "sum"`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Avg of series",
+			values:            []string{"0.4", "0.4"},
+			metadata:          `, "aggregation": "avg"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Max of series",
+			values:            []string{"0", "0.1", "2"},
+			metadata:          `, "aggregation": "max"`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Min of series",
+			values:            []string{"0", "0.1", "2"},
+			metadata:          `, "aggregation": "min"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:        "Empty result",
+			values:      []string{},
+			expectError: true,
+		},
+		{
+			name:              "Empty result as 0",
+			values:            []string{},
+			metadata:          `, "emptyResultAs": "0"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Empty result as 1 with aggregation",
+			values:            []string{},
+			metadata:          `, "aggregation": "sum", "emptyResultAs": "1"`,
+			expectScaleToZero: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				series := make([]string, 0, len(tt.values))
+				for i, value := range tt.values {
+					series = append(series, fmt.Sprintf(`{"metric": {"pod": "pod-%d"}, "value": [%d, "%s"]}`, i, time.Now().Unix(), value))
+				}
+				fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "vector", "result": [%s]}}`, strings.Join(series, ","))
+			}))
+			defer server.Close()
+
+			metadata := json.RawMessage(fmt.Sprintf(`{"serverAddress": "%s", "query": "rate(requests_total[1m])", "threshold": "0.5"%s}`, server.URL, tt.metadata))
+			scaler, err := NewPrometheusScaler(ctx, fake.NewSimpleClientset(), "monitoring", metadata, time.Minute, nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, scaleToZero)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	redisClientTimeout = 5 * time.Second
+)
+
+type redisScaler struct {
+	metadata *redisMetadata
+	client   *redis.Client
+}
+
+type redisMetadata struct {
+	Address  string `json:"address"`
+	Database int    `json:"database,string"`
+	// ListName is the list whose length (LLEN) is checked
+	ListName string `json:"listName"`
+	// StreamName is the stream whose length (XLEN) is checked, or whose pending entries are checked if ConsumerGroup is set
+	StreamName    string `json:"streamName"`
+	ConsumerGroup string `json:"consumerGroup"`
+	// thresholdMetadata is compared with the length, the threshold is 1 by default so the queue is idle once empty
+	thresholdMetadata
+	authMetadata
+}
+
+func NewRedisScaler(ctx context.Context, kClient kubernetes.Interface, namespace string, metadata json.RawMessage, cache *Cache) (Scaler, error) {
+	parsedMetadata, err := parseRedisMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating redis scaler: %w", err)
+	}
+
+	auth, err := resolveAuthConfig(ctx, kClient, namespace, &parsedMetadata.authMetadata, cache)
+	if err != nil {
+		return nil, fmt.Errorf("error creating redis scaler: %w", err)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         parsedMetadata.Address,
+		DB:           parsedMetadata.Database,
+		Username:     auth.username,
+		Password:     auth.password,
+		TLSConfig:    auth.tlsConfig,
+		DialTimeout:  redisClientTimeout,
+		ReadTimeout:  redisClientTimeout,
+		WriteTimeout: redisClientTimeout,
+		PoolSize:     1,
+	})
+
+	return &redisScaler{
+		metadata: parsedMetadata,
+		client:   client,
+	}, nil
+}
+
+func parseRedisMetadata(jsonMetadata json.RawMessage) (*redisMetadata, error) {
+	metadata := &redisMetadata{}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if metadata.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+	if (metadata.ListName == "") == (metadata.StreamName == "") {
+		return nil, fmt.Errorf("exactly one of listName or streamName is required")
+	}
+	if metadata.ConsumerGroup != "" && metadata.StreamName == "" {
+		return nil, fmt.Errorf("consumerGroup can only be used with streamName")
+	}
+	metadata.defaultThreshold(1)
+	return metadata, nil
+}
+
+func (s *redisScaler) getLength(ctx context.Context) (int64, error) {
+	switch {
+	case s.metadata.ListName != "":
+		length, err := s.client.LLen(ctx, s.metadata.ListName).Result()
+		if err != nil {
+			return -1, fmt.Errorf("failed to get length of list %s: %w", s.metadata.ListName, err)
+		}
+		return length, nil
+	case s.metadata.ConsumerGroup != "":
+		pending, err := s.client.XPending(ctx, s.metadata.StreamName, s.metadata.ConsumerGroup).Result()
+		if err != nil {
+			return -1, fmt.Errorf("failed to get pending entries of stream %s for group %s: %w", s.metadata.StreamName, s.metadata.ConsumerGroup, err)
+		}
+		return pending.Count, nil
+	default:
+		length, err := s.client.XLen(ctx, s.metadata.StreamName).Result()
+		if err != nil {
+			return -1, fmt.Errorf("failed to get length of stream %s: %w", s.metadata.StreamName, err)
+		}
+		return length, nil
+	}
+}
+
+func (s *redisScaler) ShouldScaleToZero(ctx context.Context) (bool, error) {
+	length, err := s.getLength(ctx)
+	if err != nil {
+		return false, err
+	}
+	return s.metadata.shouldScaleToZero(float64(length)), nil
+}
+
+func (s *redisScaler) ShouldScaleFromZero(ctx context.Context) (bool, error) {
+	length, err := s.getLength(ctx)
+	if err != nil {
+		return true, err
+	}
+	return s.metadata.shouldScaleFromZero(float64(length)), nil
+}
+
+func (s *redisScaler) IsHealthy(ctx context.Context) (bool, error) {
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		return false, fmt.Errorf("failed to ping redis: %w", err)
+	}
+	return true, nil
+}
+
+func (s *redisScaler) Close(_ context.Context) error {
+	if err := s.client.Close(); err != nil {
+		return fmt.Errorf("failed to close redis client: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRedisScaler(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("elasti", "secret-password")
+
+	kClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-auth", Namespace: "jobs"},
+		Data: map[string][]byte{
+			secretKeyUsername: []byte("elasti"),
+			secretKeyPassword: []byte("secret-password"),
+		},
+	})
+
+	seed := redis.NewClient(&redis.Options{Addr: server.Addr(), Username: "elasti", Password: "secret-password"})
+	defer seed.Close()
+	require.NoError(t, seed.RPush(ctx, "busy-list", "job-1", "job-2").Err())
+	require.NoError(t, seed.XAdd(ctx, &redis.XAddArgs{Stream: "events", Values: map[string]interface{}{"id": "1"}}).Err())
+	require.NoError(t, seed.XGroupCreate(ctx, "events", "idle-group", "$").Err())
+	require.NoError(t, seed.XGroupCreate(ctx, "events", "busy-group", "0").Err())
+	require.NoError(t, seed.XReadGroup(ctx, &redis.XReadGroupArgs{Group: "busy-group", Consumer: "worker", Streams: []string{"events", ">"}}).Err())
+
+	tests := []struct {
+		name              string
+		metadata          string
+		expectScaleToZero bool
+	}{
+		{
+			name:              "Empty list",
+			metadata:          `"listName": "idle-list"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:              "List with items",
+			metadata:          `"listName": "busy-list"`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "List length below threshold",
+			metadata:          `"listName": "busy-list", "threshold": "3"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:              "List length at threshold",
+			metadata:          `"listName": "busy-list", "threshold": "2"`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Stream with entries",
+			metadata:          `"streamName": "events"`,
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Stream without pending entries",
+			metadata:          `"streamName": "events", "consumerGroup": "idle-group"`,
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Stream with pending entries",
+			metadata:          `"streamName": "events", "consumerGroup": "busy-group"`,
+			expectScaleToZero: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := json.RawMessage(fmt.Sprintf(`{"address": "%s", "authSecretRef": "redis-auth", %s}`, server.Addr(), tt.metadata))
+			scaler, err := NewRedisScaler(ctx, kClient, "jobs", metadata, nil)
+			require.NoError(t, err)
+			defer scaler.Close(ctx)
+
+			healthy, err := scaler.IsHealthy(ctx)
+			require.NoError(t, err)
+			assert.True(t, healthy)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+
+			scaleFromZero, err := scaler.ShouldScaleFromZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, !tt.expectScaleToZero, scaleFromZero)
+		})
+	}
+}
+
+func TestRedisScalerAuthFailure(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("elasti", "secret-password")
+
+	metadata := json.RawMessage(fmt.Sprintf(`{"address": "%s", "listName": "jobs"}`, server.Addr()))
+	scaler, err := NewRedisScaler(ctx, fake.NewSimpleClientset(), "jobs", metadata, nil)
+	require.NoError(t, err)
+	defer scaler.Close(ctx)
+
+	healthy, err := scaler.IsHealthy(ctx)
+	assert.Error(t, err)
+	assert.False(t, healthy)
+
+	_, err = NewRedisScaler(ctx, fake.NewSimpleClientset(), "jobs", json.RawMessage(fmt.Sprintf(`{"address": "%s", "listName": "jobs", "authSecretRef": "missing"}`, server.Addr())), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Config holds everything a Factory may need to create the scaler for a trigger of an ElastiService
+type Config struct {
+	// Name and Namespace of the ElastiService
+	Name      string
+	Namespace string
+	// Metadata is the metadata of the trigger
+	Metadata       json.RawMessage
+	CooldownPeriod time.Duration
+	KubeClient     kubernetes.Interface
+	DynamicClient  dynamic.Interface
+	Cache          *Cache
+	// MetricStore holds the metrics pushed to the operator over OTLP
+	MetricStore *MetricStore
+	// LeaseStore holds the leases posted by the workloads to keep their ElastiService awake
+	LeaseStore *LeaseStore
+}
+
+// Factory creates the scaler for a trigger
+type Factory func(ctx context.Context, config *Config) (Scaler, error)
+
+// MetadataValidator checks the metadata of a trigger without creating the scaler, so no external system is contacted
+type MetadataValidator func(metadata json.RawMessage) error
+
+type registration struct {
+	factory  Factory
+	validate MetadataValidator
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+)
+
+// Register makes a scaler available for the triggers of the given type.
+// Scalers built outside of this package register themselves from an init function, like database/sql drivers do.
+// The CRD doesn't restrict the trigger types, the triggers of an ElastiService are validated against the registered ones.
+// It panics if Register is called twice with the same type or if the factory is nil.
+func Register(triggerType string, factory Factory, validate MetadataValidator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("scalers: Register factory is nil for " + triggerType)
+	}
+	if _, ok := registry[triggerType]; ok {
+		panic("scalers: Register called twice for " + triggerType)
+	}
+	registry[triggerType] = registration{factory: factory, validate: validate}
+}
+
+// New creates the scaler for a trigger of the given type
+func New(ctx context.Context, triggerType string, config *Config) (Scaler, error) {
+	registryMu.RLock()
+	r, ok := registry[triggerType]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported trigger type: %s", triggerType)
+	}
+	return r.factory(ctx, config)
+}
+
+// ValidateMetadata checks the metadata of a trigger of the given type
+func ValidateMetadata(triggerType string, metadata json.RawMessage) error {
+	registryMu.RLock()
+	r, ok := registry[triggerType]
+	registryMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unsupported trigger type: %s", triggerType)
+	}
+	if r.validate == nil {
+		return nil
+	}
+	if err := r.validate(metadata); err != nil {
+		return fmt.Errorf("invalid metadata for %s trigger: %w", triggerType, err)
+	}
+	return nil
+}
+
+// validateWith adapts a metadata parse function to a MetadataValidator
+func validateWith[T any](parse func(json.RawMessage) (T, error)) MetadataValidator {
+	return func(metadata json.RawMessage) error {
+		_, err := parse(metadata)
+		return err
+	}
+}
+
+func init() {
+	Register("prometheus", func(ctx context.Context, config *Config) (Scaler, error) {
+		return NewPrometheusScaler(ctx, config.KubeClient, config.Namespace, config.Metadata, config.CooldownPeriod, config.Cache)
+	}, validateWith(parsePrometheusMetadata))
+	Register("cron", func(_ context.Context, config *Config) (Scaler, error) {
+		return NewCronScaler(config.Metadata)
+	}, validateWith(NewCronScaler))
+	Register("kafka", func(ctx context.Context, config *Config) (Scaler, error) {
+		return NewKafkaScaler(ctx, config.KubeClient, config.Namespace, config.Metadata, config.Cache)
+	}, validateWith(parseKafkaMetadata))
+	Register("redis", func(ctx context.Context, config *Config) (Scaler, error) {
+		return NewRedisScaler(ctx, config.KubeClient, config.Namespace, config.Metadata, config.Cache)
+	}, validateWith(parseRedisMetadata))
+	Register("sql", func(ctx context.Context, config *Config) (Scaler, error) {
+		return NewSQLScaler(ctx, config.KubeClient, config.Namespace, config.Metadata, config.Cache)
+	}, validateWith(parseSQLMetadata))
+	Register("external", func(ctx context.Context, config *Config) (Scaler, error) {
+		return NewExternalScaler(ctx, config.KubeClient, config.Namespace, config.Name, config.Metadata, config.Cache)
+	}, validateWith(parseExternalMetadata))
+	Register("metrics-api", func(ctx context.Context, config *Config) (Scaler, error) {
+		return NewMetricsAPIScaler(ctx, config.KubeClient, config.Namespace, config.Metadata, config.Cache)
+	}, validateWith(parseMetricsAPIMetadata))
+	Register("kubernetes-resource", func(_ context.Context, config *Config) (Scaler, error) {
+		return NewKubernetesResourceScaler(config.DynamicClient, config.Namespace, config.Metadata, config.Cache)
+	}, validateWith(parseKubernetesResourceMetadata))
+	Register("otlp", func(_ context.Context, config *Config) (Scaler, error) {
+		return NewOTLPScaler(config.MetricStore, config.Namespace, config.Metadata)
+	}, validateWith(parseOTLPMetadata))
+	Register("heartbeat", func(_ context.Context, config *Config) (Scaler, error) {
+		return NewHeartbeatScaler(config.LeaseStore, config.Namespace, config.Name, config.Metadata)
+	}, validateWith(parseHeartbeatMetadata))
+}
@@ -0,0 +1,90 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		triggerType string
+		metadata    string
+		expectError bool
+	}{
+		{
+			name:        "Valid prometheus metadata",
+			triggerType: "prometheus",
+			metadata:    `{"serverAddress": "http://prometheus:9090", "query": "up", "threshold": "1"}`,
+		},
+		{
+			name:        "Prometheus threshold is not a number",
+			triggerType: "prometheus",
+			metadata:    `{"serverAddress": "http://prometheus:9090", "query": "up", "threshold": "high"}`,
+			expectError: true,
+		},
+		{
+			name:        "Unsupported prometheus aggregation",
+			triggerType: "prometheus",
+			metadata:    `{"serverAddress": "http://prometheus:9090", "query": "up", "aggregation": "median"}`,
+			expectError: true,
+		},
+		{
+			name:        "Invalid cron schedule",
+			triggerType: "cron",
+			metadata:    `{"timezone": "UTC", "start": "0 9 * *", "end": "0 17 * * *"}`,
+			expectError: true,
+		},
+		{
+			name:        "Missing redis address",
+			triggerType: "redis",
+			metadata:    `{"listName": "jobs"}`,
+			expectError: true,
+		},
+		{
+			name:        "Unknown trigger type",
+			triggerType: "unknown",
+			metadata:    `{}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadata(tt.triggerType, json.RawMessage(tt.metadata))
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	factory := func(_ context.Context, config *Config) (Scaler, error) {
+		return NewCronScaler(config.Metadata)
+	}
+	Register("test-private", factory, nil)
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "test-private")
+		registryMu.Unlock()
+	}()
+
+	assert.NoError(t, ValidateMetadata("test-private", json.RawMessage(`{}`)))
+
+	scaler, err := New(context.Background(), "test-private", &Config{Metadata: json.RawMessage(`{"timezone": "UTC", "start": "0 9 * * *", "end": "0 17 * * *"}`)})
+	require.NoError(t, err)
+	assert.NotNil(t, scaler)
+
+	assert.Panics(t, func() { Register("test-private", factory, nil) })
+	assert.Panics(t, func() { Register("test-nil", nil, nil) })
+
+	_, err = New(context.Background(), "unknown", &Config{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package scalers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	// Register the database drivers supported by the sql scaler
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	sqlQueryTimeout                  = 5 * time.Second
+	defaultConnectionStringSecretKey = "connectionString"
+)
+
+// sqlDrivers maps the supported driver names in the trigger metadata to the registered database/sql drivers
+var sqlDrivers = map[string]string{
+	"postgres": "postgres",
+	"mysql":    "mysql",
+}
+
+type sqlScaler struct {
+	metadata *sqlMetadata
+	db       *sql.DB
+	// cache shares the database handles between the scalers using the same connection string
+	cache *Cache
+}
+
+type sqlMetadata struct {
+	// Driver is one of postgres or mysql
+	Driver string `json:"driver"`
+	// ConnectionStringSecretRef is the name of the Secret holding the connection string
+	ConnectionStringSecretRef string `json:"connectionStringSecretRef"`
+	// ConnectionStringSecretKey is the key of the connection string in the Secret
+	ConnectionStringSecretKey string `json:"connectionStringSecretKey"`
+	// Query must return a single row with a single numeric column
+	Query string `json:"query"`
+	// thresholdMetadata is compared with the result, the threshold is required
+	thresholdMetadata
+}
+
+func NewSQLScaler(ctx context.Context, kClient kubernetes.Interface, namespace string, metadata json.RawMessage, cache *Cache) (Scaler, error) {
+	parsedMetadata, err := parseSQLMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error creating sql scaler: %w", err)
+	}
+
+	data, err := getSecretData(ctx, kClient, namespace, parsedMetadata.ConnectionStringSecretRef, cache)
+	if err != nil {
+		return nil, fmt.Errorf("error creating sql scaler: %w", err)
+	}
+	connectionString, ok := data[parsedMetadata.ConnectionStringSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("error creating sql scaler: key %s not found in secret %s/%s",
+			parsedMetadata.ConnectionStringSecretKey, namespace, parsedMetadata.ConnectionStringSecretRef)
+	}
+
+	// The handle, and so its connection, is shared through the cache by the scalers using the same database
+	driver := sqlDrivers[parsedMetadata.Driver]
+	db, err := cache.getClient("sql/"+hashValues(driver, string(connectionString)), func() (io.Closer, error) {
+		// sql.Open doesn't connect to the database, connectivity is checked in IsHealthy
+		db, err := sql.Open(driver, string(connectionString))
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxIdleTime(clientIdleTimeout)
+		return db, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating sql scaler: %w", err)
+	}
+
+	return &sqlScaler{
+		metadata: parsedMetadata,
+		db:       db.(*sql.DB),
+		cache:    cache,
+	}, nil
+}
+
+func parseSQLMetadata(jsonMetadata json.RawMessage) (*sqlMetadata, error) {
+	metadata := &sqlMetadata{}
+	err := json.Unmarshal(jsonMetadata, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if _, ok := sqlDrivers[metadata.Driver]; !ok {
+		return nil, fmt.Errorf("unsupported driver: %s", metadata.Driver)
+	}
+	if metadata.ConnectionStringSecretRef == "" {
+		return nil, fmt.Errorf("connectionStringSecretRef is required")
+	}
+	if metadata.ConnectionStringSecretKey == "" {
+		metadata.ConnectionStringSecretKey = defaultConnectionStringSecretKey
+	}
+	if metadata.Query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	if err := metadata.requireThreshold(); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// executeQuery runs the query and returns its scalar result, a NULL result is treated as 0
+func (s *sqlScaler) executeQuery(ctx context.Context) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, sqlQueryTimeout)
+	defer cancel()
+
+	var value sql.NullFloat64
+	if err := s.db.QueryRowContext(ctx, s.metadata.Query).Scan(&value); err != nil {
+		return -1, fmt.Errorf("failed to execute sql query %s: %w", s.metadata.Query, err)
+	}
+	return value.Float64, nil
+}
+
+func (s *sqlScaler) ShouldScaleToZero(ctx context.Context) (bool, error) {
+	value, err := s.executeQuery(ctx)
+	if err != nil {
+		return false, err
+	}
+	return s.metadata.shouldScaleToZero(value), nil
+}
+
+func (s *sqlScaler) ShouldScaleFromZero(ctx context.Context) (bool, error) {
+	value, err := s.executeQuery(ctx)
+	if err != nil {
+		return true, err
+	}
+	return s.metadata.shouldScaleFromZero(value), nil
+}
+
+func (s *sqlScaler) IsHealthy(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, sqlQueryTimeout)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return false, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return true, nil
+}
+
+func (s *sqlScaler) Close(_ context.Context) error {
+	// The handle is shared with the other scalers through the cache, which closes it once it is unused
+	if s.cache.shared() {
+		return nil
+	}
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database connection: %w", err)
+	}
+	return nil
+}
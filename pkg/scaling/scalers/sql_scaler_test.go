@@ -0,0 +1,139 @@
+package scalers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSQLScaler(t *testing.T) {
+	ctx := context.Background()
+	sqlDrivers["sqlmock"] = "sqlmock"
+	t.Cleanup(func() { delete(sqlDrivers, "sqlmock") })
+
+	const query = "SELECT COUNT(*) FROM jobs WHERE status = 'pending'"
+
+	tests := []struct {
+		name              string
+		rows              *sqlmock.Rows
+		expectScaleToZero bool
+	}{
+		{
+			name:              "No pending jobs",
+			rows:              sqlmock.NewRows([]string{"count"}).AddRow(0),
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Pending jobs at threshold",
+			rows:              sqlmock.NewRows([]string{"count"}).AddRow(1),
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Pending jobs",
+			rows:              sqlmock.NewRows([]string{"count"}).AddRow(3),
+			expectScaleToZero: false,
+		},
+		{
+			name:              "NULL result",
+			rows:              sqlmock.NewRows([]string{"count"}).AddRow(nil),
+			expectScaleToZero: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The DSN stored in the secret selects the mocked connection
+			_, mock, err := sqlmock.NewWithDSN(t.Name(), sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+			kClient := fake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "jobs-db", Namespace: "tools"},
+				Data:       map[string][]byte{"dsn": []byte(t.Name())},
+			})
+
+			mock.ExpectPing()
+			mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(tt.rows)
+			mock.ExpectClose()
+
+			metadata := json.RawMessage(`{"driver": "sqlmock", "connectionStringSecretRef": "jobs-db", "connectionStringSecretKey": "dsn", "query": "` + query + `", "threshold": "1"}`)
+			scaler, err := NewSQLScaler(ctx, kClient, "tools", metadata, nil)
+			require.NoError(t, err)
+
+			healthy, err := scaler.IsHealthy(ctx)
+			require.NoError(t, err)
+			assert.True(t, healthy)
+
+			scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+
+			require.NoError(t, scaler.Close(ctx))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSQLScalerSharedDB(t *testing.T) {
+	ctx := context.Background()
+	sqlDrivers["sqlmock"] = "sqlmock"
+	t.Cleanup(func() { delete(sqlDrivers, "sqlmock") })
+	cache := NewCache(10 * time.Second)
+
+	_, mock, err := sqlmock.NewWithDSN(t.Name())
+	require.NoError(t, err)
+	kClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "jobs-db", Namespace: "tools"},
+		Data:       map[string][]byte{"connectionString": []byte(t.Name())},
+	})
+	const query = "SELECT COUNT(*) FROM jobs"
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectClose()
+
+	metadata := json.RawMessage(`{"driver": "sqlmock", "connectionStringSecretRef": "jobs-db", "query": "` + query + `", "threshold": "1"}`)
+	var dbs []*sql.DB
+	for _, expectScaleToZero := range []bool{true, false} {
+		scaler, err := NewSQLScaler(ctx, kClient, "tools", metadata, cache)
+		require.NoError(t, err)
+		scaleToZero, err := scaler.ShouldScaleToZero(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expectScaleToZero, scaleToZero)
+		require.NoError(t, scaler.Close(ctx))
+		dbs = append(dbs, scaler.(*sqlScaler).db)
+	}
+
+	// The handle outlives the scalers, and is closed by the cache once it isn't used anymore
+	assert.Same(t, dbs[0], dbs[1])
+	cache.now = func() time.Time { return time.Now().Add(2 * clientIdleTimeout) }
+	cache.EvictExpired()
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewSQLScalerInvalidMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+	}{
+		{name: "Unsupported driver", metadata: `{"driver": "oracle", "connectionStringSecretRef": "db", "query": "SELECT 1"}`},
+		{name: "Missing secret ref", metadata: `{"driver": "postgres", "query": "SELECT 1"}`},
+		{name: "Missing query", metadata: `{"driver": "mysql", "connectionStringSecretRef": "db", "threshold": "1"}`},
+		{name: "Missing threshold", metadata: `{"driver": "postgres", "connectionStringSecretRef": "db", "query": "SELECT 1"}`},
+		{name: "Missing secret", metadata: `{"driver": "postgres", "connectionStringSecretRef": "db", "query": "SELECT 1", "threshold": "1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSQLScaler(context.Background(), fake.NewSimpleClientset(), "tools", json.RawMessage(tt.metadata), nil)
+			assert.Error(t, err)
+		})
+	}
+}
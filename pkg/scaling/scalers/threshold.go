@@ -0,0 +1,100 @@
+package scalers
+
+import (
+	"fmt"
+	"slices"
+)
+
+// thresholdMetadata is the metadata of the triggers comparing a single value with a threshold.
+// The service is idle while the value is below the threshold, and active once the value reaches it.
+type thresholdMetadata struct {
+	// Threshold is 0 by default, unless the trigger requires it or sets its own default
+	Threshold *float64 `json:"threshold,string"`
+	// DeactivationThreshold is optional, when set a service is scaled to zero once the value goes below it,
+	// otherwise it is scaled to zero once the value goes below the threshold
+	DeactivationThreshold *float64 `json:"deactivationThreshold,string"`
+	// ActivationThreshold is optional, when set a service at zero is scaled up once the value goes above it,
+	// otherwise it is scaled up once the value reaches the threshold
+	ActivationThreshold *float64 `json:"activationThreshold,string"`
+}
+
+// requireThreshold checks the threshold is set, for the triggers whose values have no meaningful default
+func (m *thresholdMetadata) requireThreshold() error {
+	if m.Threshold == nil {
+		return fmt.Errorf("threshold is required")
+	}
+	return nil
+}
+
+// defaultThreshold sets the threshold when it isn't set, e.g. to 1 for the triggers counting items,
+// so the service is idle once there are none
+func (m *thresholdMetadata) defaultThreshold(threshold float64) {
+	if m.Threshold == nil {
+		m.Threshold = &threshold
+	}
+}
+
+func (m *thresholdMetadata) threshold() float64 {
+	if m.Threshold == nil {
+		return 0
+	}
+	return *m.Threshold
+}
+
+// shouldScaleToZero reports whether the value is below the deactivation threshold, or the threshold
+func (m *thresholdMetadata) shouldScaleToZero(value float64) bool {
+	if m.DeactivationThreshold != nil {
+		return value < *m.DeactivationThreshold
+	}
+	return value < m.threshold()
+}
+
+// shouldScaleFromZero reports whether the value is above the activation threshold, or reaches the threshold
+func (m *thresholdMetadata) shouldScaleFromZero(value float64) bool {
+	if m.ActivationThreshold != nil {
+		return value > *m.ActivationThreshold
+	}
+	return value >= m.threshold()
+}
+
+// aggregationMetadata is the metadata of the triggers reducing several series to the single value they compare
+type aggregationMetadata struct {
+	// Aggregation is one of sum, max, min or avg, it reduces the series to a single value.
+	// When empty there must be a single series.
+	Aggregation string `json:"aggregation"`
+	// EmptyResultAs is optional, when set it is used as the value when there is no series
+	EmptyResultAs *float64 `json:"emptyResultAs,string"`
+}
+
+func (m *aggregationMetadata) validate() error {
+	return validateAggregation(m.Aggregation)
+}
+
+// aggregate reduces the non-empty values to a single value, with one of the sum, max, min or avg aggregations
+func aggregate(aggregation string, values []float64) float64 {
+	switch aggregation {
+	case "max":
+		return slices.Max(values)
+	case "min":
+		return slices.Min(values)
+	default:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		if aggregation == "avg" {
+			return sum / float64(len(values))
+		}
+		return sum
+	}
+}
+
+// validateAggregation checks the aggregation is empty or one of sum, max, min or avg
+func validateAggregation(aggregation string) error {
+	switch aggregation {
+	case "", "sum", "max", "min", "avg":
+		return nil
+	default:
+		return fmt.Errorf("unsupported aggregation: %s, must be one of sum, max, min or avg", aggregation)
+	}
+}
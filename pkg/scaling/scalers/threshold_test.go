@@ -0,0 +1,57 @@
+package scalers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdMetadata(t *testing.T) {
+	two, five, twenty := 2.0, 5.0, 20.0
+
+	tests := []struct {
+		name                      string
+		metadata                  thresholdMetadata
+		value                     float64
+		expectShouldScaleToZero   bool
+		expectShouldScaleFromZero bool
+	}{
+		{name: "Default threshold", metadata: thresholdMetadata{}, value: 0, expectShouldScaleToZero: false, expectShouldScaleFromZero: true},
+		{name: "Below default threshold", metadata: thresholdMetadata{}, value: -1, expectShouldScaleToZero: true, expectShouldScaleFromZero: false},
+		{name: "Below threshold", metadata: thresholdMetadata{Threshold: &five}, value: 4, expectShouldScaleToZero: true, expectShouldScaleFromZero: false},
+		{name: "Threshold reached", metadata: thresholdMetadata{Threshold: &five}, value: 5, expectShouldScaleToZero: false, expectShouldScaleFromZero: true},
+		{name: "Below threshold within deactivationThreshold", metadata: thresholdMetadata{Threshold: &five, DeactivationThreshold: &two}, value: 4, expectShouldScaleToZero: false, expectShouldScaleFromZero: false},
+		{name: "Below deactivationThreshold", metadata: thresholdMetadata{Threshold: &five, DeactivationThreshold: &two}, value: 1, expectShouldScaleToZero: true, expectShouldScaleFromZero: false},
+		{name: "Threshold reached within activationThreshold", metadata: thresholdMetadata{Threshold: &five, ActivationThreshold: &twenty}, value: 20, expectShouldScaleToZero: false, expectShouldScaleFromZero: false},
+		{name: "Above activationThreshold", metadata: thresholdMetadata{Threshold: &five, ActivationThreshold: &twenty}, value: 21, expectShouldScaleToZero: false, expectShouldScaleFromZero: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectShouldScaleToZero, tt.metadata.shouldScaleToZero(tt.value))
+			assert.Equal(t, tt.expectShouldScaleFromZero, tt.metadata.shouldScaleFromZero(tt.value))
+		})
+	}
+}
+
+func TestThresholdMetadataDefaults(t *testing.T) {
+	metadata := thresholdMetadata{}
+	assert.EqualError(t, metadata.requireThreshold(), "threshold is required")
+
+	metadata.defaultThreshold(1)
+	assert.NoError(t, metadata.requireThreshold())
+	assert.True(t, metadata.shouldScaleToZero(0))
+	assert.True(t, metadata.shouldScaleFromZero(1))
+
+	three := 3.0
+	metadata = thresholdMetadata{Threshold: &three}
+	metadata.defaultThreshold(1)
+	assert.Equal(t, 3.0, *metadata.Threshold)
+}
+
+func TestAggregationMetadataValidate(t *testing.T) {
+	for _, aggregation := range []string{"", "sum", "max", "min", "avg"} {
+		assert.NoError(t, (&aggregationMetadata{Aggregation: aggregation}).validate(), aggregation)
+	}
+	assert.Error(t, (&aggregationMetadata{Aggregation: "median"}).validate())
+}
@@ -0,0 +1,142 @@
+package scaling
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/google/cel-go/cel"
+	"github.com/truefoundry/elasti/pkg/scaling/scalers"
+	"k8s.io/utils/lru"
+)
+
+// celProgramCacheSize bounds the number of compiled trigger policies kept, the least recently used ones are evicted
+const celProgramCacheSize = 256
+
+// celPrograms caches the compiled CEL trigger policies by expression
+var celPrograms = lru.New(celProgramCacheSize)
+
+// triggerNames returns the names used to reference the triggers in the triggerPolicy, in the order of the triggers.
+// An unnamed trigger is named after its type, or after its type and index when that name is already taken, e.g. prometheus_1.
+// The dashes of the type are replaced with underscores, e.g. metrics_api, so the names are valid CEL identifiers.
+func triggerNames(triggers []v1alpha1.ScaleTrigger) []string {
+	defaultNames := make([]string, len(triggers))
+	counts := make(map[string]int, len(triggers))
+	for i, trigger := range triggers {
+		defaultNames[i] = strings.ReplaceAll(trigger.Type, "-", "_")
+		if trigger.Name != "" {
+			counts[trigger.Name]++
+		} else {
+			counts[defaultNames[i]]++
+		}
+	}
+
+	names := make([]string, len(triggers))
+	for i, trigger := range triggers {
+		switch {
+		case trigger.Name != "":
+			names[i] = trigger.Name
+		case counts[defaultNames[i]] > 1:
+			names[i] = fmt.Sprintf("%s_%d", defaultNames[i], i)
+		default:
+			names[i] = defaultNames[i]
+		}
+	}
+	return names
+}
+
+// ValidateTriggers checks the metadata of the triggers and the triggerPolicy, so errors surface before any polling happens
+func ValidateTriggers(spec *v1alpha1.ElastiServiceSpec) error {
+	var errs []error
+	names := triggerNames(spec.Triggers)
+	for i := range spec.Triggers {
+		trigger := &spec.Triggers[i]
+		if err := scalers.ValidateMetadata(trigger.Type, trigger.Metadata); err != nil {
+			errs = append(errs, fmt.Errorf("trigger %s: %w", names[i], err))
+		}
+	}
+
+	// Evaluating the policy also catches duplicate names and references to unknown triggers,
+	// it's evaluated with all the triggers active and all idle so short-circuits don't hide them
+	idle := make([]bool, len(names))
+	for _, allIdle := range []bool{false, true} {
+		for i := range idle {
+			idle[i] = allIdle
+		}
+		if _, err := evaluateTriggerPolicy(spec.TriggerPolicy, names, idle); err != nil {
+			errs = append(errs, err)
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// evaluateTriggerPolicy returns whether the policy allows scaling to zero, given the idle state of each trigger in the order of names.
+// Names only need to be unique for a CEL expression, as all and any don't refer to the triggers.
+func evaluateTriggerPolicy(policy string, names []string, idle []bool) (bool, error) {
+	switch policy {
+	case "", v1alpha1.TriggerPolicyAll:
+		for _, triggerIdle := range idle {
+			if !triggerIdle {
+				return false, nil
+			}
+		}
+		return true, nil
+	case v1alpha1.TriggerPolicyAny:
+		for _, triggerIdle := range idle {
+			if triggerIdle {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	triggers := make(map[string]bool, len(names))
+	for i, name := range names {
+		if _, ok := triggers[name]; ok {
+			return false, fmt.Errorf("duplicate trigger name %s, triggers referenced by the trigger policy need unique names", name)
+		}
+		triggers[name] = idle[i]
+	}
+
+	program, err := compileTriggerPolicy(policy)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := program.Eval(map[string]any{"triggers": triggers})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate trigger policy %q: %w", policy, err)
+	}
+	scaleToZero, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("trigger policy %q returned %v, expected a bool", policy, out.Value())
+	}
+	return scaleToZero, nil
+}
+
+// compileTriggerPolicy compiles the CEL expression, the idle state of the triggers is exposed as the triggers map
+func compileTriggerPolicy(policy string) (cel.Program, error) {
+	if program, ok := celPrograms.Get(policy); ok {
+		return program.(cel.Program), nil
+	}
+
+	env, err := cel.NewEnv(cel.Variable("triggers", cel.MapType(cel.StringType, cel.BoolType)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	ast, issues := env.Compile(policy)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile trigger policy %q: %w", policy, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("trigger policy %q must return a bool, got %s", policy, ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create program for trigger policy %q: %w", policy, err)
+	}
+
+	celPrograms.Add(policy, program)
+	return program, nil
+}
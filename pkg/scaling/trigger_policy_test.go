@@ -0,0 +1,255 @@
+package scaling
+
+import (
+	"fmt"
+	"testing"
+	"truefoundry/elasti/operator/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateTriggerPolicy(t *testing.T) {
+	tests := []struct {
+		name              string
+		policy            string
+		idle              map[string]bool
+		expectScaleToZero bool
+		expectError       bool
+	}{
+		{
+			name:              "Default policy with all triggers idle",
+			policy:            "",
+			idle:              map[string]bool{"requests": true, "queue": true},
+			expectScaleToZero: true,
+		},
+		{
+			name:              "All with one active trigger",
+			policy:            v1alpha1.TriggerPolicyAll,
+			idle:              map[string]bool{"requests": true, "queue": false},
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Any with one idle trigger",
+			policy:            v1alpha1.TriggerPolicyAny,
+			idle:              map[string]bool{"requests": true, "queue": false},
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Any with no idle trigger",
+			policy:            v1alpha1.TriggerPolicyAny,
+			idle:              map[string]bool{"requests": false, "queue": false},
+			expectScaleToZero: false,
+		},
+		{
+			name:              "Expression satisfied by a single trigger",
+			policy:            "(triggers.requests && triggers.queue) || triggers.nightly",
+			idle:              map[string]bool{"requests": false, "queue": true, "nightly": true},
+			expectScaleToZero: true,
+		},
+		{
+			name:              "Expression not satisfied",
+			policy:            "(triggers.requests && triggers.queue) || triggers.nightly",
+			idle:              map[string]bool{"requests": false, "queue": true, "nightly": false},
+			expectScaleToZero: false,
+		},
+		{
+			name:        "Expression with unknown trigger",
+			policy:      "triggers.requests && triggers.missing",
+			idle:        map[string]bool{"requests": true},
+			expectError: true,
+		},
+		{
+			name:        "Expression not returning a bool",
+			policy:      "size(triggers)",
+			idle:        map[string]bool{"requests": true},
+			expectError: true,
+		},
+		{
+			name:        "Invalid expression",
+			policy:      "triggers.requests &&",
+			idle:        map[string]bool{"requests": true},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := make([]string, 0, len(tt.idle))
+			idle := make([]bool, 0, len(tt.idle))
+			for name, triggerIdle := range tt.idle {
+				names = append(names, name)
+				idle = append(idle, triggerIdle)
+			}
+			scaleToZero, err := evaluateTriggerPolicy(tt.policy, names, idle)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, scaleToZero)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectScaleToZero, scaleToZero)
+		})
+	}
+}
+
+func TestEvaluateTriggerPolicyDuplicateNames(t *testing.T) {
+	names := []string{"requests", "requests"}
+	idle := []bool{true, false}
+
+	scaleToZero, err := evaluateTriggerPolicy(v1alpha1.TriggerPolicyAny, names, idle)
+	require.NoError(t, err)
+	assert.True(t, scaleToZero)
+
+	scaleToZero, err = evaluateTriggerPolicy(v1alpha1.TriggerPolicyAll, names, idle)
+	require.NoError(t, err)
+	assert.False(t, scaleToZero)
+
+	_, err = evaluateTriggerPolicy("triggers.requests", names, idle)
+	assert.ErrorContains(t, err, "duplicate trigger name requests")
+}
+
+func TestCompileTriggerPolicyCacheIsBounded(t *testing.T) {
+	for i := range celProgramCacheSize + 10 {
+		_, err := compileTriggerPolicy(fmt.Sprintf("triggers.requests || %d > 0", i))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, celProgramCacheSize, celPrograms.Len())
+}
+
+func TestTriggerNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		triggers []v1alpha1.ScaleTrigger
+		expected []string
+	}{
+		{
+			name:     "Unnamed trigger is named after its type",
+			triggers: []v1alpha1.ScaleTrigger{{Name: "requests", Type: "prometheus"}, {Type: "cron"}},
+			expected: []string{"requests", "cron"},
+		},
+		{
+			name:     "Unnamed triggers of the same type are named after their index",
+			triggers: []v1alpha1.ScaleTrigger{{Type: "prometheus"}, {Type: "prometheus"}, {Type: "cron"}},
+			expected: []string{"prometheus_0", "prometheus_1", "cron"},
+		},
+		{
+			name:     "Unnamed trigger whose type is the name of another trigger",
+			triggers: []v1alpha1.ScaleTrigger{{Name: "cron", Type: "prometheus"}, {Type: "cron"}},
+			expected: []string{"cron", "cron_1"},
+		},
+		{
+			name:     "Dashes of the type are replaced with underscores",
+			triggers: []v1alpha1.ScaleTrigger{{Type: "metrics-api"}, {Type: "metrics-api"}, {Type: "kubernetes-resource"}},
+			expected: []string{"metrics_api_0", "metrics_api_1", "kubernetes_resource"},
+		},
+		{
+			name:     "Unnamed trigger whose default name is the name of another trigger",
+			triggers: []v1alpha1.ScaleTrigger{{Name: "metrics_api", Type: "prometheus"}, {Type: "metrics-api"}},
+			expected: []string{"metrics_api", "metrics_api_1"},
+		},
+		{
+			name:     "Named triggers keep their names",
+			triggers: []v1alpha1.ScaleTrigger{{Name: "queue", Type: "redis"}, {Name: "queue", Type: "redis"}},
+			expected: []string{"queue", "queue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, triggerNames(tt.triggers))
+		})
+	}
+}
+
+func TestValidateTriggers(t *testing.T) {
+	prometheusMetadata := []byte(`{"serverAddress": "http://prometheus:9090", "query": "up", "threshold": "1"}`)
+	cronMetadata := []byte(`{"timezone": "UTC", "start": "0 9 * * *", "end": "0 17 * * *"}`)
+
+	tests := []struct {
+		name        string
+		spec        v1alpha1.ElastiServiceSpec
+		expectError bool
+	}{
+		{
+			name: "Valid triggers and expression",
+			spec: v1alpha1.ElastiServiceSpec{
+				TriggerPolicy: "triggers.requests || triggers.cron",
+				Triggers: []v1alpha1.ScaleTrigger{
+					{Name: "requests", Type: "prometheus", Metadata: prometheusMetadata},
+					{Type: "cron", Metadata: cronMetadata},
+				},
+			},
+		},
+		{
+			name: "Unnamed triggers of the same type with the default policy",
+			spec: v1alpha1.ElastiServiceSpec{
+				Triggers: []v1alpha1.ScaleTrigger{
+					{Type: "prometheus", Metadata: prometheusMetadata},
+					{Type: "prometheus", Metadata: prometheusMetadata},
+				},
+			},
+		},
+		{
+			name: "Unnamed triggers of the same type with the any policy",
+			spec: v1alpha1.ElastiServiceSpec{
+				TriggerPolicy: v1alpha1.TriggerPolicyAny,
+				Triggers: []v1alpha1.ScaleTrigger{
+					{Type: "prometheus", Metadata: prometheusMetadata},
+					{Type: "prometheus", Metadata: prometheusMetadata},
+				},
+			},
+		},
+		{
+			name: "Unnamed triggers of the same type referenced by index",
+			spec: v1alpha1.ElastiServiceSpec{
+				TriggerPolicy: "triggers.prometheus_0 && triggers.prometheus_1",
+				Triggers: []v1alpha1.ScaleTrigger{
+					{Type: "prometheus", Metadata: prometheusMetadata},
+					{Type: "prometheus", Metadata: prometheusMetadata},
+				},
+			},
+		},
+		{
+			name: "Duplicate trigger names with an expression",
+			spec: v1alpha1.ElastiServiceSpec{
+				TriggerPolicy: "triggers.requests",
+				Triggers: []v1alpha1.ScaleTrigger{
+					{Name: "requests", Type: "prometheus", Metadata: prometheusMetadata},
+					{Name: "requests", Type: "prometheus", Metadata: prometheusMetadata},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Invalid metadata",
+			spec: v1alpha1.ElastiServiceSpec{
+				Triggers: []v1alpha1.ScaleTrigger{
+					{Type: "prometheus", Metadata: []byte(`{"query": "up"}`)},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Expression referencing an unknown trigger",
+			spec: v1alpha1.ElastiServiceSpec{
+				TriggerPolicy: "triggers.requests && triggers.queue",
+				Triggers: []v1alpha1.ScaleTrigger{
+					{Name: "requests", Type: "prometheus", Metadata: prometheusMetadata},
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTriggers(&tt.spec)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
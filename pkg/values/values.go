@@ -10,9 +10,10 @@ const (
 	ArgoPhaseHealthy              = "Healthy"
 	DeploymentConditionStatusTrue = "True"
 
-	KindDeployments = "deployments"
-	KindRollout     = "rollouts"
-	KindService     = "services"
+	KindDeployments  = "deployments"
+	KindRollout      = "rollouts"
+	KindStatefulSets = "statefulsets"
+	KindService      = "services"
 
 	ServeMode = "serve"
 	ProxyMode = "proxy"
@@ -21,6 +22,8 @@ const (
 	Success = "success"
 
 	DefaultCooldownPeriod = time.Second * 900
+
+	DefaultWakeRequestsPerReplica = 10
 )
 
 var (
@@ -44,7 +44,7 @@ type (
 
 	// Operator is to communicate with the operator
 	Operator interface {
-		SendIncomingRequestInfo(ns, svc string)
+		SendIncomingRequestInfo(ns, svc string, queuedRequests int)
 	}
 
 	// HostManager is to manage the hosts, and their traffic
@@ -125,8 +125,8 @@ func (h *Handler) handleAnyRequest(w http.ResponseWriter, req *http.Request) (*m
 		return host, fmt.Errorf("traffic not allowed by resolver")
 	}
 
-	// Inform the controller about the incoming request
-	go h.operatorRPC.SendIncomingRequestInfo(host.Namespace, host.SourceService)
+	// Inform the controller about the incoming request, along with the requests already queued for the service
+	go h.operatorRPC.SendIncomingRequestInfo(host.Namespace, host.SourceService, h.throttler.GetQueueSize(host.Namespace, host.SourceService)+1)
 
 	// Send request to throttler
 	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
@@ -143,7 +143,7 @@ func (h *Handler) handleAnyRequest(w http.ResponseWriter, req *http.Request) (*m
 			h.hostManager.DisableTrafficForHost(host.IncomingHost)
 			return nil
 		}, func() {
-			h.operatorRPC.SendIncomingRequestInfo(host.Namespace, host.SourceService)
+			h.operatorRPC.SendIncomingRequestInfo(host.Namespace, host.SourceService, h.throttler.GetQueueSize(host.Namespace, host.SourceService))
 		}); tryErr != nil {
 		h.logger.Error("throttler try error: ", zap.Error(tryErr))
 		hub := sentry.GetHubFromContext(req.Context())
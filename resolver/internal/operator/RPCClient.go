@@ -42,8 +42,8 @@ func NewOperatorClient(logger *zap.Logger, retryDuration time.Duration) *Client
 	}
 }
 
-// SendIncomingRequestInfo send request details like service name to the operator
-func (o *Client) SendIncomingRequestInfo(ns, svc string) {
+// SendIncomingRequestInfo send request details like service name and the number of queued requests to the operator
+func (o *Client) SendIncomingRequestInfo(ns, svc string, queuedRequests int) {
 	lock, taken := o.getMutexForServiceRPC(svc)
 	if taken {
 		return
@@ -54,7 +54,7 @@ func (o *Client) SendIncomingRequestInfo(ns, svc string) {
 	})
 
 	requestBody := messages.RequestCount{
-		Count:     1,
+		Count:     queuedRequests,
 		Svc:       svc,
 		Namespace: ns,
 	}